@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/akshaygalande/google-air-quality-mcp/internal/config"
 	"github.com/akshaygalande/google-air-quality-mcp/internal/mcp"
@@ -12,6 +18,10 @@ import (
 func main() {
 	cfg := config.LoadConfig()
 
+	// Stop background work and the HTTP server on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize Gin
 	r := gin.Default()
 
@@ -35,11 +45,22 @@ func main() {
 	})
 
 	// Initialize MCP Server and setup Streamable HTTP
-	mcpServer := mcp.NewMCPServer(cfg.MCPServerName, "0.1.0")
+	mcpServer := mcp.NewMCPServer(ctx, cfg.MCPServerName, "0.1.0")
 	mcpServer.SetupStreamableHTTP(r)
+	mcpServer.SetupExports(r)
+
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: r}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Gin server shutdown error: %v", err)
+		}
+	}()
 
 	log.Printf("Starting Gin server with MCP Streamable HTTP on port %s...", cfg.Port)
-	if err := r.Run(":" + cfg.Port); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Gin server error: %v", err)
 	}
 }
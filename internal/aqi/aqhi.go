@@ -0,0 +1,65 @@
+package aqi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+// aqhiCoefficients are the per-pollutant risk coefficients of the Canadian
+// AQHI formula, for NO2 and O3 in ppb and PM2.5 in µg/m³
+var aqhiCoefficients = []struct {
+	code  string
+	units units.Unit
+	beta  float64
+}{
+	{code: "no2", units: units.PartsPerBillion, beta: 0.000871},
+	{code: "o3", units: units.PartsPerBillion, beta: 0.000537},
+	{code: "pm25", units: units.MicrogramsPerCubicMeter, beta: 0.000487},
+}
+
+var aqhiCategory = categories([]int{3, 6, 10}, "Low risk", "Moderate risk", "High risk", "Very high risk")
+
+// computeAQHI calculates the Canadian Air Quality Health Index, which combines
+// NO2, O3 and PM2.5 (normally 3-hour means) into a single additive risk:
+//
+//	AQHI = 10/10.4 × 100 × Σ (exp(β × C) − 1)
+//
+// Each sub-index reports the pollutant's contribution in AQHI units.
+func computeAQHI(concentrations map[string]Concentration) (*Result, error) {
+	const name = "Canada Air Quality Health Index (AQHI)"
+	result := &Result{Standard: StandardCAAQHI, Name: name}
+	total, largest := 0.0, 0.0
+	for _, coef := range aqhiCoefficients {
+		c, ok := concentrations[coef.code]
+		if !ok {
+			return nil, fmt.Errorf("%s: needs NO2, O3 and PM2.5, missing %s", name, coef.code)
+		}
+		value, err := convert(coef.code, c, coef.units)
+		if err != nil {
+			return nil, err
+		}
+		contribution := 10 / 10.4 * 100 * (math.Exp(coef.beta*value) - 1)
+		total += contribution
+		if contribution > largest {
+			largest, result.DominantPollutant = contribution, coef.code
+		}
+		result.SubIndexes = append(result.SubIndexes, SubIndex{
+			Pollutant:     coef.code,
+			Concentration: math.Round(value*100) / 100,
+			Units:         coef.units.Symbol(),
+			Value:         int(math.Round(contribution)),
+		})
+	}
+
+	// The index starts at 1 and is published as "10+" above 10
+	result.Value = max(1, int(math.Round(total)))
+	result.Display = strconv.Itoa(result.Value)
+	if result.Value > 10 {
+		result.Display = "10+"
+	}
+	result.Category = aqhiCategory(result.Value)
+	return result, nil
+}
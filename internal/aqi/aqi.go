@@ -0,0 +1,150 @@
+// Package aqi computes national air quality indexes from pollutant concentrations
+package aqi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+// Standard identifies an air quality index
+type Standard string
+
+const (
+	StandardUSEPA  Standard = "US_EPA"
+	StandardEUCAQI Standard = "EU_CAQI"
+	StandardUKDAQI Standard = "UK_DAQI"
+	StandardINNAQI Standard = "IN_NAQI"
+	StandardCNAQI  Standard = "CN_AQI"
+	StandardCAAQHI Standard = "CA_AQHI"
+)
+
+// Standards lists every index this package can compute
+var Standards = []Standard{
+	StandardUSEPA,
+	StandardEUCAQI,
+	StandardUKDAQI,
+	StandardINNAQI,
+	StandardCNAQI,
+	StandardCAAQHI,
+}
+
+// ParseStandard validates an index name
+func ParseStandard(s string) (Standard, error) {
+	for _, std := range Standards {
+		if string(std) == s {
+			return std, nil
+		}
+	}
+	return "", fmt.Errorf("unknown index %q, expected one of %v", s, Standards)
+}
+
+// Concentration is a pollutant concentration
+type Concentration struct {
+	Value float64
+	Units units.Unit
+}
+
+// SubIndex is the index value computed for a single pollutant
+type SubIndex struct {
+	Pollutant string `json:"pollutant"`
+	// Concentration is the value used for the lookup, converted and truncated
+	// as the index requires
+	Concentration float64 `json:"concentration"`
+	Units         string  `json:"units"`
+	Value         int     `json:"value"`
+	Category      string  `json:"category,omitempty"`
+	// AboveScale is set when the concentration is beyond the highest breakpoint
+	AboveScale bool `json:"aboveScale,omitempty"`
+}
+
+// Result is a computed index
+type Result struct {
+	Standard Standard `json:"standard"`
+	Name     string   `json:"name"`
+	Value    int      `json:"value"`
+	// Display is the value as the index publishes it, e.g. "10+" for AQHI
+	Display           string     `json:"display"`
+	Category          string     `json:"category"`
+	DominantPollutant string     `json:"dominantPollutant,omitempty"`
+	SubIndexes        []SubIndex `json:"subIndexes,omitempty"`
+}
+
+// Compute calculates an index from concentrations keyed by API pollutant code
+// (pm25, pm10, o3, no2, so2, co, nh3). Concentrations are used as given, so
+// callers should supply the averaging period the index expects where possible.
+func Compute(standard Standard, concentrations map[string]Concentration) (*Result, error) {
+	if standard == StandardCAAQHI {
+		return computeAQHI(concentrations)
+	}
+	def, ok := definitions[standard]
+	if !ok {
+		return nil, fmt.Errorf("unknown index %q", standard)
+	}
+
+	result := &Result{Standard: standard, Name: def.name, Value: -1}
+	for _, code := range sortedCodes(concentrations) {
+		sc, ok := def.scales[code]
+		if !ok {
+			continue
+		}
+		c, err := convert(code, concentrations[code], sc.units)
+		if err != nil {
+			return nil, err
+		}
+		if sc.decimals >= 0 {
+			c = truncate(c, sc.decimals)
+		}
+		value, above := sc.lookup(c)
+		index := def.round(value)
+		if def.maxIndex > 0 && index > def.maxIndex {
+			index = def.maxIndex
+		}
+		sub := SubIndex{
+			Pollutant:     code,
+			Concentration: math.Round(c*1000) / 1000,
+			Units:         sc.units.Symbol(),
+			Value:         index,
+			Category:      def.category(index),
+			AboveScale:    above,
+		}
+		result.SubIndexes = append(result.SubIndexes, sub)
+		if index > result.Value {
+			result.Value, result.DominantPollutant = index, code
+		}
+	}
+	if len(result.SubIndexes) == 0 {
+		return nil, fmt.Errorf("%s: no supported pollutant concentrations", def.name)
+	}
+	if def.validate != nil {
+		if err := def.validate(result.SubIndexes); err != nil {
+			return nil, fmt.Errorf("%s: %w", def.name, err)
+		}
+	}
+	result.Category = def.category(result.Value)
+	result.Display = strconv.Itoa(result.Value)
+	if def.minDominant > 0 && result.Value <= def.minDominant {
+		result.DominantPollutant = ""
+	}
+	return result, nil
+}
+
+// truncate drops digits beyond the given number of decimal places
+func truncate(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	// Nudge by a tiny amount so values such as 0.07 (0.06999...) keep their digits
+	return math.Floor(v*p+1e-9) / p
+}
+
+// sortedCodes returns pollutant codes in sorted order
+func sortedCodes(concentrations map[string]Concentration) []string {
+	codes := make([]string, 0, len(concentrations))
+	for code := range concentrations {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,203 @@
+package aqi
+
+import (
+	"testing"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+var (
+	ugm3 = units.MicrogramsPerCubicMeter
+	mgm3 = units.MilligramsPerCubicMeter
+	ppb  = units.PartsPerBillion
+	ppm  = units.PartsPerMillion
+)
+
+// TestCategoryEdges checks every standard on both sides of its published
+// category breakpoints
+func TestCategoryEdges(t *testing.T) {
+	// NAQI needs three pollutants including PM2.5 or PM10, so its cases are
+	// padded with clean air
+	clean := map[string]Concentration{"no2": {0, ugm3}, "o3": {0, ugm3}, "pm10": {0, ugm3}}
+
+	tests := []struct {
+		standard      Standard
+		pollutant     string
+		c             Concentration
+		value         int
+		category      string
+		aboveScale    bool
+		checkDominant bool
+	}{
+		// US EPA, PM2.5 (2024 breakpoints), truncated to 0.1 µg/m³
+		{StandardUSEPA, "pm25", Concentration{0, ugm3}, 0, "Good", false, false},
+		{StandardUSEPA, "pm25", Concentration{9.0, ugm3}, 50, "Good", false, false},
+		{StandardUSEPA, "pm25", Concentration{9.09, ugm3}, 50, "Good", false, false},
+		{StandardUSEPA, "pm25", Concentration{9.1, ugm3}, 51, "Moderate", false, false},
+		{StandardUSEPA, "pm25", Concentration{35.4, ugm3}, 100, "Moderate", false, false},
+		{StandardUSEPA, "pm25", Concentration{35.5, ugm3}, 101, "Unhealthy for Sensitive Groups", false, false},
+		{StandardUSEPA, "pm25", Concentration{55.4, ugm3}, 150, "Unhealthy for Sensitive Groups", false, false},
+		{StandardUSEPA, "pm25", Concentration{55.5, ugm3}, 151, "Unhealthy", false, false},
+		{StandardUSEPA, "pm25", Concentration{125.4, ugm3}, 200, "Unhealthy", false, false},
+		{StandardUSEPA, "pm25", Concentration{125.5, ugm3}, 201, "Very Unhealthy", false, false},
+		{StandardUSEPA, "pm25", Concentration{225.4, ugm3}, 300, "Very Unhealthy", false, false},
+		{StandardUSEPA, "pm25", Concentration{225.5, ugm3}, 301, "Hazardous", false, false},
+		{StandardUSEPA, "pm25", Concentration{325.4, ugm3}, 500, "Hazardous", false, false},
+		{StandardUSEPA, "pm25", Concentration{400, ugm3}, 500, "Hazardous", true, false},
+		// US EPA, 8-hour ozone in ppm truncated to 0.001, also given in ppb
+		{StandardUSEPA, "o3", Concentration{0.054, ppm}, 50, "Good", false, false},
+		{StandardUSEPA, "o3", Concentration{0.055, ppm}, 51, "Moderate", false, false},
+		{StandardUSEPA, "o3", Concentration{70, ppb}, 100, "Moderate", false, false},
+		{StandardUSEPA, "o3", Concentration{70.9, ppb}, 100, "Moderate", false, false},
+		{StandardUSEPA, "o3", Concentration{71, ppb}, 101, "Unhealthy for Sensitive Groups", false, false},
+		{StandardUSEPA, "o3", Concentration{0.200, ppm}, 300, "Very Unhealthy", false, false},
+		{StandardUSEPA, "o3", Concentration{0.405, ppm}, 301, "Hazardous", false, false},
+		// US EPA, PM10 and gases
+		{StandardUSEPA, "pm10", Concentration{54, ugm3}, 50, "Good", false, false},
+		{StandardUSEPA, "pm10", Concentration{55, ugm3}, 51, "Moderate", false, false},
+		{StandardUSEPA, "co", Concentration{9.4, ppm}, 100, "Moderate", false, false},
+		{StandardUSEPA, "co", Concentration{9.5, ppm}, 101, "Unhealthy for Sensitive Groups", false, false},
+		{StandardUSEPA, "so2", Concentration{75, ppb}, 100, "Moderate", false, false},
+		{StandardUSEPA, "so2", Concentration{76, ppb}, 101, "Unhealthy for Sensitive Groups", false, false},
+		{StandardUSEPA, "no2", Concentration{360, ppb}, 150, "Unhealthy for Sensitive Groups", false, false},
+		{StandardUSEPA, "no2", Concentration{361, ppb}, 151, "Unhealthy", false, false},
+
+		// EU CAQI, hourly background grid
+		{StandardEUCAQI, "pm25", Concentration{15, ugm3}, 25, "Very low", false, false},
+		{StandardEUCAQI, "pm25", Concentration{16, ugm3}, 27, "Low", false, false},
+		{StandardEUCAQI, "pm25", Concentration{30, ugm3}, 50, "Low", false, false},
+		{StandardEUCAQI, "pm25", Concentration{55, ugm3}, 75, "Medium", false, false},
+		{StandardEUCAQI, "pm25", Concentration{110, ugm3}, 100, "High", false, false},
+		{StandardEUCAQI, "pm25", Concentration{120, ugm3}, 105, "Very high", true, false},
+		{StandardEUCAQI, "no2", Concentration{50, ugm3}, 25, "Very low", false, false},
+		{StandardEUCAQI, "no2", Concentration{400, ugm3}, 100, "High", false, false},
+		{StandardEUCAQI, "o3", Concentration{120, ugm3}, 50, "Low", false, false},
+		{StandardEUCAQI, "pm10", Concentration{90, ugm3}, 75, "Medium", false, false},
+
+		// UK DAQI, bands of whole µg/m³
+		{StandardUKDAQI, "pm25", Concentration{11, ugm3}, 1, "Low", false, false},
+		{StandardUKDAQI, "pm25", Concentration{12, ugm3}, 2, "Low", false, false},
+		{StandardUKDAQI, "pm25", Concentration{35.9, ugm3}, 3, "Low", false, false},
+		{StandardUKDAQI, "pm25", Concentration{36, ugm3}, 4, "Moderate", false, false},
+		{StandardUKDAQI, "pm25", Concentration{53, ugm3}, 6, "Moderate", false, false},
+		{StandardUKDAQI, "pm25", Concentration{54, ugm3}, 7, "High", false, false},
+		{StandardUKDAQI, "pm25", Concentration{70, ugm3}, 9, "High", false, false},
+		{StandardUKDAQI, "pm25", Concentration{71, ugm3}, 10, "Very High", false, false},
+		{StandardUKDAQI, "o3", Concentration{100, ugm3}, 3, "Low", false, false},
+		{StandardUKDAQI, "o3", Concentration{101, ugm3}, 4, "Moderate", false, false},
+		{StandardUKDAQI, "pm10", Concentration{75, ugm3}, 6, "Moderate", false, false},
+		{StandardUKDAQI, "pm10", Concentration{76, ugm3}, 7, "High", false, false},
+		{StandardUKDAQI, "no2", Concentration{601, ugm3}, 10, "Very High", false, false},
+
+		// India NAQI, 24-hour means
+		{StandardINNAQI, "pm25", Concentration{30, ugm3}, 50, "Good", false, false},
+		{StandardINNAQI, "pm25", Concentration{31, ugm3}, 52, "Satisfactory", false, false},
+		{StandardINNAQI, "pm25", Concentration{60, ugm3}, 100, "Satisfactory", false, false},
+		{StandardINNAQI, "pm25", Concentration{90, ugm3}, 200, "Moderate", false, false},
+		{StandardINNAQI, "pm25", Concentration{120, ugm3}, 300, "Poor", false, false},
+		{StandardINNAQI, "pm25", Concentration{250, ugm3}, 400, "Very Poor", false, false},
+		{StandardINNAQI, "pm25", Concentration{300, ugm3}, 438, "Severe", true, false},
+		{StandardINNAQI, "pm25", Concentration{1000, ugm3}, 500, "Severe", true, false},
+		{StandardINNAQI, "pm10", Concentration{100, ugm3}, 100, "Satisfactory", false, false},
+		{StandardINNAQI, "pm10", Concentration{101, ugm3}, 101, "Moderate", false, false},
+		{StandardINNAQI, "co", Concentration{10, mgm3}, 200, "Moderate", false, false},
+
+		// China AQI, individual indexes rounded up
+		{StandardCNAQI, "pm25", Concentration{35, ugm3}, 50, "Excellent", false, true},
+		{StandardCNAQI, "pm25", Concentration{35.1, ugm3}, 51, "Good", false, true},
+		{StandardCNAQI, "pm25", Concentration{75, ugm3}, 100, "Good", false, true},
+		{StandardCNAQI, "pm25", Concentration{76, ugm3}, 102, "Lightly Polluted", false, true},
+		{StandardCNAQI, "pm25", Concentration{115, ugm3}, 150, "Lightly Polluted", false, true},
+		{StandardCNAQI, "pm25", Concentration{150, ugm3}, 200, "Moderately Polluted", false, true},
+		{StandardCNAQI, "pm25", Concentration{250, ugm3}, 300, "Heavily Polluted", false, true},
+		{StandardCNAQI, "pm25", Concentration{251, ugm3}, 301, "Severely Polluted", false, true},
+		{StandardCNAQI, "pm25", Concentration{500, ugm3}, 500, "Severely Polluted", false, true},
+		{StandardCNAQI, "pm25", Concentration{600, ugm3}, 500, "Severely Polluted", true, true},
+		{StandardCNAQI, "o3", Concentration{160, ugm3}, 50, "Excellent", false, true},
+		{StandardCNAQI, "o3", Concentration{200, ugm3}, 100, "Good", false, true},
+		{StandardCNAQI, "co", Concentration{10, mgm3}, 100, "Good", false, true},
+	}
+	for _, tt := range tests {
+		concentrations := map[string]Concentration{tt.pollutant: tt.c}
+		if tt.standard == StandardINNAQI {
+			for code, c := range clean {
+				if code != tt.pollutant {
+					concentrations[code] = c
+				}
+			}
+		}
+		got, err := Compute(tt.standard, concentrations)
+		if err != nil {
+			t.Errorf("%s %s %v: %v", tt.standard, tt.pollutant, tt.c, err)
+			continue
+		}
+		if got.Value != tt.value || got.Category != tt.category {
+			t.Errorf("%s %s %v = %d %q, want %d %q", tt.standard, tt.pollutant, tt.c, got.Value, got.Category, tt.value, tt.category)
+		}
+		for _, sub := range got.SubIndexes {
+			if sub.Pollutant == tt.pollutant && sub.AboveScale != tt.aboveScale {
+				t.Errorf("%s %s %v above scale = %v, want %v", tt.standard, tt.pollutant, tt.c, sub.AboveScale, tt.aboveScale)
+			}
+		}
+		// China reports no dominant pollutant while the index is Excellent
+		if tt.checkDominant && (got.DominantPollutant == "") != (tt.value <= 50) {
+			t.Errorf("%s %s %v dominant pollutant = %q", tt.standard, tt.pollutant, tt.c, got.DominantPollutant)
+		}
+	}
+}
+
+// TestAQHIEdges checks the Canadian AQHI on both sides of its risk
+// categories, with PM2.5 alone contributing
+func TestAQHIEdges(t *testing.T) {
+	tests := []struct {
+		pm25     float64
+		value    int
+		display  string
+		category string
+	}{
+		{0, 1, "1", "Low risk"},
+		{71, 3, "3", "Low risk"},
+		{76, 4, "4", "Moderate risk"},
+		{132, 6, "6", "Moderate risk"},
+		{137, 7, "7", "High risk"},
+		{210, 10, "10", "High risk"},
+		{215, 11, "10+", "Very high risk"},
+	}
+	for _, tt := range tests {
+		got, err := Compute(StandardCAAQHI, map[string]Concentration{
+			"no2":  {0, ppb},
+			"o3":   {0, ppb},
+			"pm25": {tt.pm25, ugm3},
+		})
+		if err != nil {
+			t.Fatalf("AQHI pm25 %v: %v", tt.pm25, err)
+		}
+		if got.Value != tt.value || got.Display != tt.display || got.Category != tt.category {
+			t.Errorf("AQHI pm25 %v = %d %q %q, want %d %q %q", tt.pm25, got.Value, got.Display, got.Category, tt.value, tt.display, tt.category)
+		}
+	}
+
+	if _, err := Compute(StandardCAAQHI, map[string]Concentration{"pm25": {10, ugm3}}); err == nil {
+		t.Error("AQHI without NO2 and O3 succeeded, want error")
+	}
+}
+
+func TestComputeErrors(t *testing.T) {
+	tests := []struct {
+		standard       Standard
+		concentrations map[string]Concentration
+	}{
+		{"XX", map[string]Concentration{"pm25": {10, ugm3}}},
+		{StandardUSEPA, map[string]Concentration{"nh3": {10, ugm3}}},
+		// NAQI needs three pollutants including PM2.5 or PM10
+		{StandardINNAQI, map[string]Concentration{"pm25": {10, ugm3}, "no2": {10, ugm3}}},
+		{StandardINNAQI, map[string]Concentration{"no2": {10, ugm3}, "o3": {10, ugm3}, "so2": {10, ugm3}}},
+		// Particulates cannot be converted to a mixing ratio
+		{StandardUSEPA, map[string]Concentration{"pm25": {10, ppb}}},
+	}
+	for _, tt := range tests {
+		if got, err := Compute(tt.standard, tt.concentrations); err == nil {
+			t.Errorf("Compute(%s, %v) = %d, want error", tt.standard, tt.concentrations, got.Value)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package aqi
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+// convert expresses a concentration in the given unit. Index breakpoints are
+// defined at 25°C and 1 atm, so conversions use standard conditions.
+func convert(code string, c Concentration, to units.Unit) (float64, error) {
+	return units.ConvertAt(code, c.Value, c.Units, to, units.StandardConditions)
+}
+
+// segment maps the concentration range [cLo, cHi] linearly onto [iLo, iHi]
+type segment struct {
+	cLo, cHi float64
+	iLo, iHi float64
+}
+
+// scale is the breakpoint table of one pollutant within an index
+type scale struct {
+	units units.Unit
+	// decimals is how many decimal places concentrations are truncated to
+	// before the lookup, or -1 for no truncation
+	decimals int
+	segments []segment
+}
+
+// lookup interpolates the index value for a concentration. Concentrations
+// beyond the last breakpoint are extrapolated along the last segment and
+// reported as above scale.
+func (s scale) lookup(c float64) (float64, bool) {
+	for _, seg := range s.segments {
+		if c <= seg.cHi {
+			// Truncated tables leave gaps between segments; snap to the lower bound
+			c = math.Max(c, seg.cLo)
+			return seg.iLo + (seg.iHi-seg.iLo)*(c-seg.cLo)/(seg.cHi-seg.cLo), false
+		}
+	}
+	last := s.segments[len(s.segments)-1]
+	return last.iLo + (last.iHi-last.iLo)*(c-last.cLo)/(last.cHi-last.cLo), true
+}
+
+// contiguous builds segments joining consecutive breakpoints, so that each
+// concentration breakpoint maps onto the index breakpoint at the same position
+func contiguous(concentrations, indexes []float64) []segment {
+	segments := make([]segment, 0, len(concentrations)-1)
+	for i := 1; i < len(concentrations); i++ {
+		segments = append(segments, segment{
+			cLo: concentrations[i-1], cHi: concentrations[i],
+			iLo: indexes[i-1], iHi: indexes[i],
+		})
+	}
+	return segments
+}
+
+// bands builds segments for banded indexes where each integer concentration
+// range maps to a single band number, starting at band 1
+func bands(uppers ...float64) []segment {
+	segments := make([]segment, 0, len(uppers))
+	lo := 0.0
+	for i, hi := range uppers {
+		band := float64(i + 1)
+		segments = append(segments, segment{cLo: lo, cHi: hi, iLo: band, iHi: band})
+		lo = hi + 1
+	}
+	return segments
+}
+
+// categories returns a function naming the category of an index value, where
+// uppers holds the inclusive upper bound of every category but the last
+func categories(uppers []int, names ...string) func(int) string {
+	return func(value int) string {
+		for i, upper := range uppers {
+			if value <= upper {
+				return names[i]
+			}
+		}
+		return names[len(names)-1]
+	}
+}
+
+// definition describes how an index is computed from breakpoint tables
+type definition struct {
+	name     string
+	scales   map[string]scale
+	round    func(float64) int
+	category func(int) string
+	// maxIndex caps computed values when positive
+	maxIndex int
+	// minDominant is the value at or below which no dominant pollutant is reported
+	minDominant int
+	validate    func([]SubIndex) error
+}
+
+func roundNearest(v float64) int { return int(math.Round(v)) }
+
+var definitions = map[Standard]definition{
+	StandardUSEPA: {
+		name: "US EPA AQI",
+		scales: map[string]scale{
+			"pm25": {units: units.MicrogramsPerCubicMeter, decimals: 1, segments: []segment{
+				{0.0, 9.0, 0, 50}, {9.1, 35.4, 51, 100}, {35.5, 55.4, 101, 150},
+				{55.5, 125.4, 151, 200}, {125.5, 225.4, 201, 300}, {225.5, 325.4, 301, 500},
+			}},
+			"pm10": {units: units.MicrogramsPerCubicMeter, decimals: 0, segments: []segment{
+				{0, 54, 0, 50}, {55, 154, 51, 100}, {155, 254, 101, 150},
+				{255, 354, 151, 200}, {355, 424, 201, 300}, {425, 604, 301, 500},
+			}},
+			// 8-hour ozone breakpoints up to 300, then the 1-hour table, which is
+			// the only one defining values above 300
+			"o3": {units: units.PartsPerMillion, decimals: 3, segments: []segment{
+				{0.000, 0.054, 0, 50}, {0.055, 0.070, 51, 100}, {0.071, 0.085, 101, 150},
+				{0.086, 0.105, 151, 200}, {0.106, 0.200, 201, 300}, {0.201, 0.404, 300, 300},
+				{0.405, 0.604, 301, 500},
+			}},
+			"co": {units: units.PartsPerMillion, decimals: 1, segments: []segment{
+				{0.0, 4.4, 0, 50}, {4.5, 9.4, 51, 100}, {9.5, 12.4, 101, 150},
+				{12.5, 15.4, 151, 200}, {15.5, 30.4, 201, 300}, {30.5, 50.4, 301, 500},
+			}},
+			"so2": {units: units.PartsPerBillion, decimals: 0, segments: []segment{
+				{0, 35, 0, 50}, {36, 75, 51, 100}, {76, 185, 101, 150},
+				{186, 304, 151, 200}, {305, 604, 201, 300}, {605, 1004, 301, 500},
+			}},
+			"no2": {units: units.PartsPerBillion, decimals: 0, segments: []segment{
+				{0, 53, 0, 50}, {54, 100, 51, 100}, {101, 360, 101, 150},
+				{361, 649, 151, 200}, {650, 1249, 201, 300}, {1250, 2049, 301, 500},
+			}},
+		},
+		round:    roundNearest,
+		category: categories([]int{50, 100, 150, 200, 300}, "Good", "Moderate", "Unhealthy for Sensitive Groups", "Unhealthy", "Very Unhealthy", "Hazardous"),
+		maxIndex: 500,
+	},
+	StandardEUCAQI: {
+		// Hourly background grid of the Common Air Quality Index
+		name: "EU Common Air Quality Index (CAQI)",
+		scales: map[string]scale{
+			"no2":  {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 50, 100, 200, 400}, caqiLevels)},
+			"pm10": {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 25, 50, 90, 180}, caqiLevels)},
+			"o3":   {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 60, 120, 180, 240}, caqiLevels)},
+			"pm25": {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 15, 30, 55, 110}, caqiLevels)},
+			"co":   {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 5000, 7500, 10000, 20000}, caqiLevels)},
+			"so2":  {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 50, 100, 350, 500}, caqiLevels)},
+		},
+		round:    roundNearest,
+		category: categories([]int{25, 50, 75, 100}, "Very low", "Low", "Medium", "High", "Very high"),
+	},
+	StandardUKDAQI: {
+		name: "UK Daily Air Quality Index (DAQI)",
+		scales: map[string]scale{
+			"o3":   {units: units.MicrogramsPerCubicMeter, decimals: 0, segments: bands(33, 66, 100, 120, 140, 160, 187, 213, 240, math.Inf(1))},
+			"no2":  {units: units.MicrogramsPerCubicMeter, decimals: 0, segments: bands(67, 134, 200, 267, 334, 400, 467, 534, 600, math.Inf(1))},
+			"so2":  {units: units.MicrogramsPerCubicMeter, decimals: 0, segments: bands(88, 177, 266, 354, 443, 532, 710, 887, 1064, math.Inf(1))},
+			"pm25": {units: units.MicrogramsPerCubicMeter, decimals: 0, segments: bands(11, 23, 35, 41, 47, 53, 58, 64, 70, math.Inf(1))},
+			"pm10": {units: units.MicrogramsPerCubicMeter, decimals: 0, segments: bands(16, 33, 50, 58, 66, 75, 83, 91, 100, math.Inf(1))},
+		},
+		round:    roundNearest,
+		category: categories([]int{3, 6, 9}, "Low", "Moderate", "High", "Very High"),
+	},
+	StandardINNAQI: {
+		// The Severe band is open-ended, so values above Very Poor are
+		// extrapolated and capped at 500
+		name: "India National Air Quality Index (NAQI)",
+		scales: map[string]scale{
+			"pm10": {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 50, 100, 250, 350, 430}, naqiLevels)},
+			"pm25": {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 30, 60, 90, 120, 250}, naqiLevels)},
+			"no2":  {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 40, 80, 180, 280, 400}, naqiLevels)},
+			"o3":   {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 50, 100, 168, 208, 748}, naqiLevels)},
+			"co":   {units: units.MilligramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 1, 2, 10, 17, 34}, naqiLevels)},
+			"so2":  {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 40, 80, 380, 800, 1600}, naqiLevels)},
+			"nh3":  {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 200, 400, 800, 1200, 1800}, naqiLevels)},
+		},
+		round:    roundNearest,
+		category: categories([]int{50, 100, 200, 300, 400}, "Good", "Satisfactory", "Moderate", "Poor", "Very Poor", "Severe"),
+		maxIndex: 500,
+		validate: func(subs []SubIndex) error {
+			hasPM := false
+			for _, s := range subs {
+				hasPM = hasPM || s.Pollutant == "pm25" || s.Pollutant == "pm10"
+			}
+			if len(subs) < 3 || !hasPM {
+				return fmt.Errorf("needs at least three pollutants including PM2.5 or PM10")
+			}
+			return nil
+		},
+	},
+	StandardCNAQI: {
+		// 1-hour tables for gases (HJ 633-2012); SO2 continues with the 24-hour
+		// table above 800 µg/m³ as the standard prescribes
+		name: "China Air Quality Index (HJ 633-2012)",
+		scales: map[string]scale{
+			"so2":  {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 150, 500, 650, 800, 1600, 2100, 2620}, cnLevels)},
+			"no2":  {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 100, 200, 700, 1200, 2340, 3090, 3840}, cnLevels)},
+			"pm10": {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 50, 150, 250, 350, 420, 500, 600}, cnLevels)},
+			"co":   {units: units.MilligramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 5, 10, 35, 60, 90, 120, 150}, cnLevels)},
+			"o3":   {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 160, 200, 300, 400, 800, 1000, 1200}, cnLevels)},
+			"pm25": {units: units.MicrogramsPerCubicMeter, decimals: -1, segments: contiguous([]float64{0, 35, 75, 115, 150, 250, 350, 500}, cnLevels)},
+		},
+		// Individual indexes are rounded up
+		round:       func(v float64) int { return int(math.Ceil(v)) },
+		category:    categories([]int{50, 100, 150, 200, 300}, "Excellent", "Good", "Lightly Polluted", "Moderately Polluted", "Heavily Polluted", "Severely Polluted"),
+		maxIndex:    500,
+		minDominant: 50,
+	},
+}
+
+var (
+	caqiLevels = []float64{0, 25, 50, 75, 100}
+	naqiLevels = []float64{0, 50, 100, 200, 300, 400}
+	cnLevels   = []float64{0, 50, 100, 150, 200, 300, 400, 500}
+)
@@ -0,0 +1,98 @@
+package aqi
+
+import (
+	"fmt"
+	"math"
+)
+
+// nowcastRule configures the NowCast for one pollutant
+type nowcastRule struct {
+	// hours is how many recent hours the average spans
+	hours int
+	// minWeight is the lower bound of the weight factor
+	minWeight float64
+}
+
+// nowcastRules holds the EPA NowCast parameters: 12 hours with a minimum
+// weight factor of 0.5 for particulates, 8 hours with no minimum for ozone
+var nowcastRules = map[string]nowcastRule{
+	"pm25": {hours: 12, minWeight: 0.5},
+	"pm10": {hours: 12, minWeight: 0.5},
+	"o3":   {hours: 8, minWeight: 0},
+}
+
+// NowCastHours returns how many hours of data the NowCast of a pollutant uses,
+// or 0 if the pollutant has no NowCast
+func NowCastHours(pollutant string) int {
+	return nowcastRules[pollutant].hours
+}
+
+// NowCastResult is a NowCast concentration and how it was weighted
+type NowCastResult struct {
+	Concentration float64 `json:"concentration"`
+	// WeightFactor is the ratio applied per hour of age; values near 1 mean
+	// stable air and a plain average, low values favour the latest hours
+	WeightFactor float64 `json:"weightFactor"`
+	HoursUsed    int     `json:"hoursUsed"`
+	Hours        int     `json:"hours"`
+}
+
+// NowCast computes the EPA NowCast of hourly concentrations ordered from the
+// most recent hour backwards, with math.NaN() marking missing hours. Two of
+// the three most recent hours must be present, and none may be negative.
+func NowCast(pollutant string, hourly []float64) (*NowCastResult, error) {
+	rule, ok := nowcastRules[pollutant]
+	if !ok {
+		return nil, fmt.Errorf("NowCast is not defined for %s", pollutant)
+	}
+	if len(hourly) > rule.hours {
+		hourly = hourly[:rule.hours]
+	}
+
+	recent := 0
+	for i := 0; i < len(hourly) && i < 3; i++ {
+		if !math.IsNaN(hourly[i]) {
+			recent++
+		}
+	}
+	if recent < 2 {
+		return nil, fmt.Errorf("NowCast for %s needs at least two of the three most recent hours", pollutant)
+	}
+
+	lo, hi, used, first := math.Inf(1), math.Inf(-1), 0, -1
+	for i, c := range hourly {
+		if math.IsNaN(c) {
+			continue
+		}
+		if c < 0 {
+			return nil, fmt.Errorf("NowCast for %s needs non-negative concentrations, got %v", pollutant, c)
+		}
+		if first < 0 {
+			first = i
+		}
+		lo, hi, used = math.Min(lo, c), math.Max(hi, c), used+1
+	}
+	weight := 1.0
+	if hi > 0 {
+		weight = math.Max(lo/hi, rule.minWeight)
+	}
+
+	// Ages count from the latest present hour. Scaling every weight alike
+	// leaves the average unchanged, and a weight factor of 0, possible for
+	// ozone, then gives that hour's value rather than 0/0.
+	sum, norm := 0.0, 0.0
+	for i, c := range hourly {
+		if math.IsNaN(c) {
+			continue
+		}
+		w := math.Pow(weight, float64(i-first))
+		sum += w * c
+		norm += w
+	}
+	return &NowCastResult{
+		Concentration: math.Round(sum/norm*10) / 10,
+		WeightFactor:  math.Round(weight*1000) / 1000,
+		HoursUsed:     used,
+		Hours:         rule.hours,
+	}, nil
+}
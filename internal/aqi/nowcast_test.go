@@ -0,0 +1,92 @@
+package aqi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNowCast(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name      string
+		pollutant string
+		hourly    []float64
+		want      float64
+		weight    float64
+		used      int
+	}{
+		{"stable air is a plain average", "pm25", []float64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10}, 10, 1, 12},
+		{"clean air", "pm25", []float64{0, 0, 0}, 0, 1, 3},
+		// min/max = 0.8, so hour i weighs 0.8^i
+		{"weight is min over max", "pm25", []float64{10, 8}, 9.1, 0.8, 2},
+		// min/max = 0.25 is raised to the particulate minimum of 0.5
+		{"particulate weight floor", "pm25", []float64{40, 10}, 30, 0.5, 2},
+		{"pm10 weight floor", "pm10", []float64{40, 10}, 30, 0.5, 2},
+		// Ozone has no minimum weight
+		{"ozone has no floor", "o3", []float64{40, 10}, 34, 0.25, 2},
+		{"rising pm25", "pm25", []float64{13, 16, 10, 21, 74, 64, 53, 82, 90, 75, 80, 50}, 17.4, 0.5, 12},
+		// Missing hours are skipped and keep the age of the hours after them
+		{"missing hour", "pm25", []float64{20, nan, 10}, 18, 0.5, 2},
+		{"missing latest hour", "pm25", []float64{nan, 20, 10}, 16.7, 0.5, 2},
+		// A zero hour gives ozone a weight factor of 0, which leaves only the
+		// latest present hour
+		{"ozone weight of zero", "o3", []float64{nan, 5, 0, 4, 3, 2, 1, 1}, 5, 0, 7},
+		{"ozone weight of zero with latest hour", "o3", []float64{6, 5, 0, 4}, 6, 0, 4},
+		// Hours beyond the NowCast window are ignored
+		{"pm25 uses 12 hours", "pm25", []float64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 500}, 10, 1, 12},
+		{"ozone uses 8 hours", "o3", []float64{50, 50, 50, 50, 50, 50, 50, 50, 1}, 50, 1, 8},
+	}
+	for _, tt := range tests {
+		got, err := NowCast(tt.pollutant, tt.hourly)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got.Concentration != tt.want || got.WeightFactor != tt.weight || got.HoursUsed != tt.used {
+			t.Errorf("%s: NowCast = %v weight %v from %d hours, want %v weight %v from %d hours",
+				tt.name, got.Concentration, got.WeightFactor, got.HoursUsed, tt.want, tt.weight, tt.used)
+		}
+		if got.Hours != NowCastHours(tt.pollutant) {
+			t.Errorf("%s: window %d hours, want %d", tt.name, got.Hours, NowCastHours(tt.pollutant))
+		}
+	}
+}
+
+// TestNowCastRecentHours checks that two of the three most recent hours
+// must be present and that no hour may be negative
+func TestNowCastRecentHours(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		hourly []float64
+		ok     bool
+	}{
+		{[]float64{10, 10, 10}, true},
+		{[]float64{10, 10}, true},
+		{[]float64{nan, 10, 10, 10}, true},
+		{[]float64{10, nan, 10}, true},
+		{[]float64{10, 10, nan, nan}, true},
+		{[]float64{10}, false},
+		{[]float64{}, false},
+		{[]float64{nan, nan, 10, 10, 10, 10}, false},
+		{[]float64{10, nan, nan, 10, 10, 10}, false},
+		{[]float64{nan, 10, nan, 10, 10, 10}, false},
+		// Negative concentrations would make the weight factor negative
+		{[]float64{10, -1, 10}, false},
+		{[]float64{10, 10, 10, -5}, false},
+	}
+	for _, tt := range tests {
+		_, err := NowCast("pm25", tt.hourly)
+		if (err == nil) != tt.ok {
+			t.Errorf("NowCast(%v) error = %v, want ok %v", tt.hourly, err, tt.ok)
+		}
+	}
+}
+
+func TestNowCastUnknownPollutant(t *testing.T) {
+	if _, err := NowCast("no2", []float64{10, 10, 10}); err == nil {
+		t.Error("NowCast of no2 succeeded, want error")
+	}
+	if NowCastHours("no2") != 0 {
+		t.Errorf("NowCastHours(no2) = %d, want 0", NowCastHours("no2"))
+	}
+}
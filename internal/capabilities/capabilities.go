@@ -1,20 +1,39 @@
 package capabilities
 
 import (
+	"context"
+
 	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/prompts"
 	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/resources"
 	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// RegisterAll registers all MCP features (tools, prompts, resources) with the server
-func RegisterAll(server *mcp.Server) {
+// RegisterAll registers all MCP features (tools, prompts, resources) with the
+// server. Background work stops when ctx is done.
+func RegisterAll(ctx context.Context, server *mcp.Server) {
 	// Register all tools
-	tools.RegisterAll(server)
+	tools.RegisterAll(ctx, server)
 
 	// Register all prompts
 	prompts.RegisterAll(server)
 
 	// Register all resources
-	resources.RegisterAll(server)
+	resources.RegisterAll(ctx, server)
+}
+
+// ServerOptions returns the server options the registered features rely on
+func ServerOptions() *mcp.ServerOptions {
+	return &mcp.ServerOptions{
+		SubscribeHandler:   resources.SubscribeHandler,
+		UnsubscribeHandler: resources.UnsubscribeHandler,
+		InitializedHandler: func(ctx context.Context, request *mcp.InitializedRequest) {
+			// Drop what the session kept on the server once it closes
+			session := request.Session
+			go func() {
+				session.Wait()
+				tools.SessionClosed(session.ID())
+			}()
+		},
+	}
 }
@@ -112,7 +112,7 @@ func AirQualityHeatmapByLocationHandler(ctx context.Context, request *mcp.GetPro
 	}
 
 	promptText := fmt.Sprintf("Please get the air quality heatmap tile for %s using map type '%s' at zoom level %s.", location, mapType, zoom)
-	promptText += " You should first determine the latitude and longitude for this location, convert them to the appropriate X and Y tile coordinates for the given zoom level, and then use the 'get_air_quality_heatmap_tile' tool."
+	promptText += " You should first determine the latitude and longitude for this location, and then use the 'get_air_quality_heatmap_tile' tool with the latitude, longitude and zoom. The server converts them to tile coordinates."
 
 	return &mcp.GetPromptResult{
 		Description: "Prompt to get air quality heatmap tile for a location",
@@ -42,7 +42,7 @@ func RegisterAll(server *mcp.Server) {
 	// Prompt for air quality heatmap tile by location name
 	server.AddPrompt(&mcp.Prompt{
 		Name:        "air_quality_heatmap_by_location_prompt",
-		Description: "Get heatmap tile image for a location name (LLM will convert to latitude/longitude and tile coordinates)",
+		Description: "Get heatmap tile image for a location name (LLM will convert to latitude/longitude, the server computes tile coordinates)",
 		Arguments:   []*mcp.PromptArgument{{Name: "location", Description: "Human readable location name", Required: true}, {Name: "mapType", Description: "Type of heatmap (e.g., UAQI_RED_GREEN)", Required: true}, {Name: "zoom", Description: "Zoom level (0-16)", Required: true}},
 	}, AirQualityHeatmapByLocationHandler)
 }
@@ -2,11 +2,12 @@ package resources
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -24,39 +25,54 @@ func NewAirQualityResourceHandler(apiKey string) *AirQualityResourceHandler {
 	}
 }
 
+// currentResource is current conditions with the time they were fetched
+type currentResource struct {
+	*tools.CurrentConditionsResponse
+	PolledAt string `json:"polledAt"`
+}
+
 // CurrentConditionsHandler handles requests for current air quality conditions
 // URI: airquality://current/{lat},{long}
 func (h *AirQualityResourceHandler) CurrentConditionsHandler(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 	uri := request.Params.URI
 	fmt.Printf("DEBUG: CurrentConditionsHandler called with URI: %s\n", uri)
-	prefix := "airquality://current/"
-	if !strings.HasPrefix(uri, prefix) {
+	if !strings.HasPrefix(uri, currentURIPrefix) {
 		return nil, fmt.Errorf("invalid URI format")
 	}
-	location := strings.TrimPrefix(uri, prefix)
-
-	lat, lon, err := parseLatLong(location)
+	lat, lon, projection, err := parseLocationQuery(strings.TrimPrefix(uri, currentURIPrefix))
 	if err != nil {
 		return nil, err
 	}
 
-	// Helper to create bool pointer
-	truePtr := true
-
-	req := tools.CurrentConditionsRequest{
-		Location: tools.LatLng{
-			Latitude:  lat,
-			Longitude: lon,
-		},
-		UniversalAqi: &truePtr, // Default to true
-	}
+	// Serve watched locations from the latest poll while it is up to date;
+	// polls don't include concentrations
+	resp, polledAt, ok := watched.latest(lat, lon)
+	if !ok || projection.NeedsConcentrations() {
+		// Helper to create bool pointer
+		truePtr := true
+
+		req := tools.CurrentConditionsRequest{
+			Location: tools.LatLng{
+				Latitude:  lat,
+				Longitude: lon,
+			},
+			UniversalAqi: &truePtr, // Default to true
+		}
+		if projection.NeedsConcentrations() {
+			req.ExtraComputations = []tools.ExtraComputation{tools.ExtraComputationPollutantConcentration}
+		}
 
-	resp, err := h.client.GetCurrentConditions(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current conditions: %w", err)
+		resp, err = h.client.GetCurrentConditions(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current conditions: %w", err)
+		}
+		polledAt = time.Now()
 	}
 
-	jsonBytes, err := json.MarshalIndent(resp, "", "  ")
+	jsonBytes, err := json.MarshalIndent(currentResource{
+		CurrentConditionsResponse: projection.Current(resp),
+		PolledAt:                  polledAt.UTC().Format(time.RFC3339),
+	}, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -81,9 +97,7 @@ func (h *AirQualityResourceHandler) ForecastHandler(ctx context.Context, request
 	if !strings.HasPrefix(uri, prefix) {
 		return nil, fmt.Errorf("invalid URI format")
 	}
-	location := strings.TrimPrefix(uri, prefix)
-
-	lat, lon, err := parseLatLong(location)
+	lat, lon, projection, err := parseLocationQuery(strings.TrimPrefix(uri, prefix))
 	if err != nil {
 		return nil, err
 	}
@@ -98,13 +112,16 @@ func (h *AirQualityResourceHandler) ForecastHandler(ctx context.Context, request
 		},
 		UniversalAqi: &truePtr,
 	}
+	if projection.NeedsConcentrations() {
+		req.ExtraComputations = []tools.ExtraComputation{tools.ExtraComputationPollutantConcentration}
+	}
 
-	resp, err := h.client.GetForecast(req)
+	resp, err := tools.FetchForecast(h.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get forecast: %w", err)
 	}
 
-	jsonBytes, err := json.MarshalIndent(resp, "", "  ")
+	jsonBytes, err := json.MarshalIndent(projection.Forecast(resp), "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -129,9 +146,7 @@ func (h *AirQualityResourceHandler) HistoryHandler(ctx context.Context, request
 	if !strings.HasPrefix(uri, prefix) {
 		return nil, fmt.Errorf("invalid URI format")
 	}
-	location := strings.TrimPrefix(uri, prefix)
-
-	lat, lon, err := parseLatLong(location)
+	lat, lon, projection, err := parseLocationQuery(strings.TrimPrefix(uri, prefix))
 	if err != nil {
 		return nil, err
 	}
@@ -148,13 +163,16 @@ func (h *AirQualityResourceHandler) HistoryHandler(ctx context.Context, request
 		Hours:        24,
 		UniversalAqi: &truePtr,
 	}
+	if projection.NeedsConcentrations() {
+		req.ExtraComputations = []tools.ExtraComputation{tools.ExtraComputationPollutantConcentration}
+	}
 
 	resp, err := h.client.GetHistory(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get history: %w", err)
 	}
 
-	jsonBytes, err := json.MarshalIndent(resp, "", "  ")
+	jsonBytes, err := json.MarshalIndent(projection.History(resp), "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -172,6 +190,7 @@ func (h *AirQualityResourceHandler) HistoryHandler(ctx context.Context, request
 
 // HeatmapHandler handles requests for heatmap tiles
 // URI: airquality://heatmap/{mapType}/{z}/{x}/{y}
+// URI: airquality://heatmap/{mapType}/{z}/{lat},{long}
 func (h *AirQualityResourceHandler) HeatmapHandler(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 	uri := request.Params.URI
 	fmt.Printf("DEBUG: HeatmapHandler called with URI: %s\n", uri)
@@ -183,71 +202,117 @@ func (h *AirQualityResourceHandler) HeatmapHandler(ctx context.Context, request
 	// Remove prefix and split by /
 	path := strings.TrimPrefix(uri, prefix)
 	parts := strings.Split(path, "/")
-	if len(parts) != 4 {
-		return nil, fmt.Errorf("invalid heatmap URI format, expected {mapType}/{z}/{x}/{y}")
+	if len(parts) != 4 && len(parts) != 3 {
+		return nil, fmt.Errorf("invalid heatmap URI format, expected {mapType}/{z}/{x}/{y} or {mapType}/{z}/{lat},{long}")
 	}
 
 	mapTypeStr := parts[0]
-	zStr := parts[1]
-	xStr := parts[2]
-	yStr := parts[3]
-
-	zoom, err := strconv.Atoi(zStr)
+	zoom, err := strconv.Atoi(parts[1])
 	if err != nil {
 		return nil, fmt.Errorf("invalid zoom level: %w", err)
 	}
-	x, err := strconv.Atoi(xStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid x coordinate: %w", err)
+
+	var x, y int
+	var point *tools.TilePixel
+	if len(parts) == 3 {
+		// Convert latitude/longitude to the covering tile
+		lat, lon, err := parseLatLong(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		if err := tools.ValidateLatLng(lat, lon); err != nil {
+			return nil, err
+		}
+		var pixel tools.TilePixel
+		x, y, pixel = tools.LatLngToTile(lat, lon, zoom)
+		point = &pixel
+	} else {
+		x, err = strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err = strconv.Atoi(parts[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
 	}
-	y, err := strconv.Atoi(yStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid y coordinate: %w", err)
+	if err := tools.ValidateTile(zoom, x, y); err != nil {
+		return nil, err
 	}
 
 	// Validate map type
-	validMapType := false
-	// This list should match the types in tools/heatmap.go or client.go
-	validTypes := []string{
-		"UAQI_RED_GREEN", "UAQI_INDIGO_PERSIAN",
-		"PM25_INDIGO_PERSIAN", "GBR_DEFRA",
-		"DEU_UBA", "CAN_EC", "FRA_ATMO", "US_AQI",
-	}
-	for _, t := range validTypes {
-		if mapTypeStr == t {
-			validMapType = true
-			break
-		}
-	}
-	if !validMapType {
-		return nil, fmt.Errorf("invalid map type: %s", mapTypeStr)
+	mapType, err := tools.ParseMapType(mapTypeStr)
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := h.client.GetHeatmapTile(tools.MapType(mapTypeStr), zoom, x, y)
+	data, err := h.client.GetHeatmapTile(mapType, zoom, x, y)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get heatmap tile: %w", err)
 	}
 
-	// Encode to base64 for text transport, or use Blob if supported.
-	// MCP spec allows Blob, but go-sdk ResourceContents has Blob field?
-	// Let's check ResourceContents definition again.
-	// The view_file of examples.go showed Text field.
-	// Let's assume Text with base64 for now if Blob isn't obvious, OR check if Blob is available.
-	// Wait, I didn't check ResourceContents struct fully.
-	// I'll use base64 in Text for safety as it's common for binary in JSON-RPC.
-	encoded := base64.StdEncoding.EncodeToString(data)
+	// Describe the tile so callers can place it on a map
+	info := tools.HeatmapTileInfo{
+		MapType: mapTypeStr,
+		Zoom:    zoom,
+		X:       x,
+		Y:       y,
+		Bounds:  tools.TileBoundsFor(zoom, x, y),
+		Point:   point,
+	}
+	jsonInfo, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tile info: %w", err)
+	}
 
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
 				URI:      uri,
 				MIMEType: "image/png",
-				Text:     encoded,
+				Blob:     data,
+			},
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(jsonInfo),
 			},
 		},
 	}, nil
 }
 
+// parseLocationQuery parses "lat,long" optionally followed by fields and
+// pollutants query parameters with comma-separated values
+func parseLocationQuery(s string) (float64, float64, *tools.Projection, error) {
+	location, rawQuery, _ := strings.Cut(s, "?")
+	lat, lon, err := parseLatLong(location)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid query: %w", err)
+	}
+	projection, err := tools.ParseProjection(queryList(query, "fields"), queryList(query, "pollutants"))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return lat, lon, projection, nil
+}
+
+// queryList returns the comma-separated values of a query parameter
+func queryList(query url.Values, key string) []string {
+	var values []string
+	for _, v := range query[key] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
 func parseLatLong(s string) (float64, float64, error) {
 	parts := strings.Split(s, ",")
 	if len(parts) != 2 {
@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AlertHandler handles requests for alert state
+// URI: airquality://alerts/{id}
+func AlertHandler(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := request.Params.URI
+	if !strings.HasPrefix(uri, tools.AlertURIPrefix) {
+		return nil, fmt.Errorf("invalid URI format")
+	}
+
+	alert, err := tools.Alerts.Get(strings.TrimPrefix(uri, tools.AlertURIPrefix))
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	jsonBytes, err := json.MarshalIndent(alert, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(jsonBytes),
+			},
+		},
+	}, nil
+}
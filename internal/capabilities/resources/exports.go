@@ -0,0 +1,28 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ExportHandler handles requests for exported files until they expire
+// URI: airquality://exports/{id}
+func ExportHandler(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := request.Params.URI
+	if !strings.HasPrefix(uri, tools.ExportURIPrefix) {
+		return nil, fmt.Errorf("invalid URI format")
+	}
+
+	e, ok := tools.Exports.Get(strings.TrimPrefix(uri, tools.ExportURIPrefix))
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{tools.ExportContents(e)},
+	}, nil
+}
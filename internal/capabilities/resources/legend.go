@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"strings"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LegendHandler handles requests for heatmap legends
+// URI: airquality://legend/{mapType}
+func LegendHandler(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := request.Params.URI
+	prefix := "airquality://legend/"
+	if !strings.HasPrefix(uri, prefix) {
+		return nil, fmt.Errorf("invalid URI format")
+	}
+
+	mapType, err := tools.ParseMapType(strings.TrimPrefix(uri, prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	legend, err := tools.NewLegend(mapType)
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := json.MarshalIndent(legend, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal legend: %w", err)
+	}
+
+	img, err := tools.RenderLegendPNG(mapType)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode legend: %w", err)
+	}
+
+	svg, err := tools.RenderLegendSVG(mapType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "image/png",
+				Blob:     buf.Bytes(),
+			},
+			{
+				URI:      uri,
+				MIMEType: "image/svg+xml",
+				Text:     svg,
+			},
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(jsonBytes),
+			},
+		},
+	}, nil
+}
@@ -2,12 +2,17 @@ package resources
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/config"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// RegisterAll registers all resources with the MCP server
-func RegisterAll(server *mcp.Server) {
+// RegisterAll registers all resources with the MCP server. Watched locations
+// are polled until ctx is done.
+func RegisterAll(ctx context.Context, server *mcp.Server) {
 	// Register a simple static resource as an example
 	server.AddResource(&mcp.Resource{
 		URI:         "example://server-info",
@@ -16,6 +21,95 @@ func RegisterAll(server *mcp.Server) {
 		MIMEType:    "text/plain",
 	}, ServerInfoHandler)
 
+	// Load configuration to get API key
+	cfg := config.LoadConfig()
+	handler := NewAirQualityResourceHandler(cfg.APIKey)
+
+	// Keep current conditions of the configured locations fresh
+	startWatcher(ctx, server, cfg)
+
+	// Register Air Quality API resource templates
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://current/{lat},{long}{?fields,pollutants}",
+		Name:        "Current Air Quality",
+		Description: "Current air quality conditions for a location with the time they were polled, served from the latest poll for watched locations while it is up to date",
+		MIMEType:    "application/json",
+	}, handler.CurrentConditionsHandler)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://forecast/{lat},{long}{?fields,pollutants}",
+		Name:        "Air Quality Forecast",
+		Description: "Hourly air quality forecast for a location",
+		MIMEType:    "application/json",
+	}, handler.ForecastHandler)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://history/{lat},{long}{?fields,pollutants}",
+		Name:        "Air Quality History",
+		Description: "Past 24 hours of air quality for a location",
+		MIMEType:    "application/json",
+	}, handler.HistoryHandler)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://heatmap/{mapType}/{zoom}/{x}/{y}",
+		Name:        "Air Quality Heatmap Tile",
+		Description: "Heatmap tile image by tile coordinates",
+		MIMEType:    "image/png",
+	}, handler.HeatmapHandler)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://heatmap/{mapType}/{zoom}/{lat},{long}",
+		Name:        "Air Quality Heatmap Tile by Location",
+		Description: "Heatmap tile image covering a latitude/longitude",
+		MIMEType:    "image/png",
+	}, handler.HeatmapHandler)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://legend/{mapType}",
+		Name:        "Air Quality Heatmap Legend",
+		Description: "Color legend for a heatmap map type as PNG, SVG and JSON",
+		MIMEType:    "image/png",
+	}, LegendHandler)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://alerts/{id}",
+		Name:        "Air Quality Alert",
+		Description: "State and recent events of an alert created with create_air_quality_alert; subscribe to be notified of changes",
+		MIMEType:    "application/json",
+	}, AlertHandler)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "airquality://exports/{id}",
+		Name:        "Air Quality Export",
+		Description: "File created with export_air_quality, available until it expires",
+		MIMEType:    "text/csv",
+	}, ExportHandler)
+}
+
+// SubscribeHandler accepts subscriptions to existing alerts and to the
+// current conditions of watched locations
+func SubscribeHandler(ctx context.Context, request *mcp.SubscribeRequest) error {
+	uri := request.Params.URI
+	switch {
+	case strings.HasPrefix(uri, tools.AlertURIPrefix):
+		if _, err := tools.Alerts.Get(strings.TrimPrefix(uri, tools.AlertURIPrefix)); err != nil {
+			return mcp.ResourceNotFoundError(uri)
+		}
+		return nil
+	case strings.HasPrefix(uri, currentURIPrefix):
+		lat, lon, _, err := parseLocationQuery(strings.TrimPrefix(uri, currentURIPrefix))
+		if err != nil {
+			return err
+		}
+		return watched.subscribe(uri, lat, lon)
+	default:
+		return fmt.Errorf("subscriptions are only supported for %s{id} and watched %s{lat},{long} resources", tools.AlertURIPrefix, currentURIPrefix)
+	}
+}
+
+// UnsubscribeHandler accepts all unsubscriptions; the server tracks them itself
+func UnsubscribeHandler(ctx context.Context, request *mcp.UnsubscribeRequest) error {
+	return nil
 }
 
 // ServerInfoHandler provides basic server information as a simple example resource
@@ -26,10 +120,14 @@ func ServerInfoHandler(ctx context.Context, request *mcp.ReadResourceRequest) (*
 This server provides air quality data through the Google Air Quality API.
 
 Available Resources:
-- airquality://current/{lat},{long} - Current air quality conditions
+- airquality://current/{lat},{long} - Current air quality conditions (subscribable for watched locations)
 - airquality://forecast/{lat},{long} - Air quality forecast
 - airquality://history/{lat},{long} - Historical air quality data
+  (append ?fields=indexes.aqi,pollutants.concentration&pollutants=uaqi,pm25 to trim the response)
 - airquality://heatmap/{mapType}/{zoom}/{x}/{y} - Heatmap tiles
+- airquality://heatmap/{mapType}/{zoom}/{lat},{long} - Heatmap tile covering a point
+- airquality://legend/{mapType} - Color legend for a heatmap map type
+- airquality://alerts/{id} - Threshold alert state (subscribable)
 
 Example: airquality://current/37.7749,-122.4194
 `
@@ -0,0 +1,97 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/config"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/watcher"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// currentURIPrefix is the URI prefix of current conditions resources
+	currentURIPrefix = "airquality://current/"
+	// maxWatchedAge is how old a polled result may be before current
+	// conditions are fetched live instead; the API updates hourly
+	maxWatchedAge = time.Hour
+)
+
+// watchedLocations serves current conditions of watched locations from the
+// latest poll and remembers the URIs clients subscribed to them under, since
+// the same location can be written in more than one way
+type watchedLocations struct {
+	watcher *watcher.Watcher
+
+	mu   sync.Mutex
+	uris map[string]map[string]bool
+}
+
+// watched is set up by RegisterAll from the configured watch locations
+var watched = &watchedLocations{uris: map[string]map[string]bool{}}
+
+// startWatcher starts polling the configured locations until ctx is done and
+// notifies subscribers of their current conditions resources after each poll
+func startWatcher(ctx context.Context, server *mcp.Server, cfg *config.Config) {
+	w, err := watcher.New(tools.NewClient(cfg.APIKey), cfg.WatchLocations, cfg.WatchJitter, cfg.WatchMaxBackoff)
+	if err != nil {
+		log.Printf("Watcher disabled: %v", err)
+		return
+	}
+	watched.watcher = w
+	w.Start(ctx, func(location tools.LatLng) {
+		for _, uri := range watched.subscribedURIs(location.Latitude, location.Longitude) {
+			if err := server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+				log.Printf("Failed to notify subscribers of %s: %v", uri, err)
+			}
+		}
+	})
+}
+
+// latest returns the polled conditions of a watched location and when they
+// were polled, unless they are out of date
+func (w *watchedLocations) latest(lat, lon float64) (*tools.CurrentConditionsResponse, time.Time, bool) {
+	if w.watcher == nil {
+		return nil, time.Time{}, false
+	}
+	result, ok := w.watcher.Fresh(lat, lon, maxWatchedAge, time.Now())
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return result.Response, result.PolledAt, true
+}
+
+// subscribe records a subscription URI for a watched location
+func (w *watchedLocations) subscribe(uri string, lat, lon float64) error {
+	if w.watcher == nil || !w.watcher.Watching(lat, lon) {
+		return fmt.Errorf("location %v,%v is not watched, add it to WATCH_LOCATIONS to subscribe", lat, lon)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := watcher.Key(lat, lon)
+	if w.uris[key] == nil {
+		w.uris[key] = map[string]bool{}
+	}
+	w.uris[key][uri] = true
+	return nil
+}
+
+// subscribedURIs returns the canonical URI of a location plus every URI it
+// was subscribed under
+func (w *watchedLocations) subscribedURIs(lat, lon float64) []string {
+	canonical := currentURIPrefix + strconv.FormatFloat(lat, 'f', -1, 64) + "," + strconv.FormatFloat(lon, 'f', -1, 64)
+	uris := []string{canonical}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for uri := range w.uris[watcher.Key(lat, lon)] {
+		if uri != canonical {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
@@ -0,0 +1,414 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertURIPrefix is the URI prefix of alert resources
+const AlertURIPrefix = "airquality://alerts/"
+
+// maxAlertEvents is the number of most recent events an alert keeps
+const maxAlertEvents = 50
+
+// AlertDirection is the side of the threshold that triggers an alert
+type AlertDirection string
+
+const (
+	AlertAbove AlertDirection = "above"
+	AlertBelow AlertDirection = "below"
+)
+
+// AlertState is the state of an alert after its last check
+type AlertState string
+
+const (
+	AlertPending   AlertState = "pending"
+	AlertNormal    AlertState = "normal"
+	AlertTriggered AlertState = "triggered"
+)
+
+// AlertEvent records a state change or forecast crossing of an alert
+type AlertEvent struct {
+	Time   string     `json:"time"`
+	Type   string     `json:"type"`
+	Value  float64    `json:"value"`
+	At     string     `json:"at"`
+	Source TimeSource `json:"source"`
+}
+
+// Alert watches an index or pollutant at a location against a threshold.
+// Once triggered it only clears after the value moves back past the
+// threshold by the hysteresis, so values hovering around it don't flap.
+type Alert struct {
+	ID             string         `json:"id"`
+	URI            string         `json:"uri"`
+	Location       LatLng         `json:"location"`
+	Index          string         `json:"index,omitempty"`
+	Pollutant      string         `json:"pollutant,omitempty"`
+	Direction      AlertDirection `json:"direction"`
+	Threshold      float64        `json:"threshold"`
+	Hysteresis     float64        `json:"hysteresis"`
+	LookaheadHours int            `json:"lookaheadHours,omitempty"`
+	CreatedAt      string         `json:"createdAt"`
+	ExpiresAt      string         `json:"expiresAt"`
+
+	State            AlertState   `json:"state"`
+	Value            *float64     `json:"value,omitempty"`
+	Units            string       `json:"units,omitempty"`
+	CheckedAt        string       `json:"checkedAt,omitempty"`
+	LastError        string       `json:"lastError,omitempty"`
+	ForecastCrossing string       `json:"forecastCrossing,omitempty"`
+	Events           []AlertEvent `json:"events"`
+
+	// session is the MCP session that created the alert
+	session string
+	expires time.Time
+}
+
+// crosses reports whether a value is on the triggering side of the threshold
+func (a *Alert) crosses(v float64) bool {
+	if a.Direction == AlertBelow {
+		return v <= a.Threshold
+	}
+	return v >= a.Threshold
+}
+
+// recovered reports whether a value is back past the threshold by the hysteresis
+func (a *Alert) recovered(v float64) bool {
+	if a.Direction == AlertBelow {
+		return v > a.Threshold+a.Hysteresis
+	}
+	return v < a.Threshold-a.Hysteresis
+}
+
+// measure reads the alert's value from one hour of data
+func (a *Alert) measure(indexes []AQI, pollutants []Pollutant) (float64, string, bool) {
+	if a.Pollutant != "" {
+		for _, p := range pollutants {
+			if p.Code == a.Pollutant && p.Concentration != nil {
+				return p.Concentration.Value, p.Concentration.Units, true
+			}
+		}
+		return 0, "", false
+	}
+	var idx *AQI
+	switch strings.ToUpper(a.Index) {
+	case "UAQI":
+		idx = findIndex(indexes, universalAqiCode)
+	case "LOCAL":
+		idx = localIndex(indexes)
+	default:
+		idx = findIndex(indexes, a.Index)
+	}
+	if idx == nil {
+		return 0, "", false
+	}
+	return float64(idx.Aqi), "", true
+}
+
+// alertReading is the outcome of fetching the data an alert needs
+type alertReading struct {
+	at       string
+	value    float64
+	units    string
+	crossing *AlertEvent
+	err      error
+}
+
+// AlertManager keeps alerts and polls them in the background. Every alert
+// costs API calls on each poll, so alerts expire after a TTL, each session
+// may hold a limited number and a session's alerts go when it closes.
+type AlertManager struct {
+	mu            sync.Mutex
+	alerts        map[string]*Alert
+	client        *Client
+	notify        func(uri string)
+	ttl           time.Duration
+	maxPerSession int
+}
+
+// NewAlertManager creates an empty alert manager
+func NewAlertManager() *AlertManager {
+	return &AlertManager{alerts: map[string]*Alert{}, ttl: 24 * time.Hour, maxPerSession: 10}
+}
+
+// SetLimits sets how long new alerts live and how many a session may hold
+func (m *AlertManager) SetLimits(ttl time.Duration, maxPerSession int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ttl, m.maxPerSession = ttl, maxPerSession
+}
+
+// Alerts holds the alerts created through create_air_quality_alert
+var Alerts = NewAlertManager()
+
+// Start polls all alerts every interval until ctx is done, calling notify with
+// the URI of each alert whose state or forecast crossing changed
+func (m *AlertManager) Start(ctx context.Context, client *Client, interval time.Duration, notify func(uri string)) {
+	m.mu.Lock()
+	m.client, m.notify = client, notify
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.poll()
+			}
+		}
+	}()
+}
+
+// Create stores a new alert for a session and checks it once
+func (m *AlertManager) Create(alert Alert, session string) (*Alert, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	alert.ID = hex.EncodeToString(id)
+	alert.URI = AlertURIPrefix + alert.ID
+	alert.CreatedAt = now.Format(time.RFC3339)
+	alert.State = AlertPending
+	alert.Events = []AlertEvent{}
+	alert.session = session
+
+	m.mu.Lock()
+	m.removeExpired(now)
+	if m.maxPerSession > 0 && len(m.sessionAlerts(session)) >= m.maxPerSession {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("a session can hold at most %d alerts, delete one with %s first", m.maxPerSession, DeleteAlertToolName)
+	}
+	alert.expires = now.Add(m.ttl)
+	alert.ExpiresAt = alert.expires.Format(time.RFC3339)
+	m.alerts[alert.ID] = &alert
+	m.mu.Unlock()
+
+	m.check(alert.ID)
+	return m.Get(alert.ID)
+}
+
+// Get returns a copy of the alert with the given ID
+func (m *AlertManager) Get(id string) (*Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alert, ok := m.alerts[id]
+	if !ok || !time.Now().Before(alert.expires) {
+		return nil, fmt.Errorf("alert %q not found", id)
+	}
+	return alert.copy(), nil
+}
+
+// List returns copies of a session's alerts, oldest first
+func (m *AlertManager) List(session string) []*Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeExpired(time.Now())
+	var out []*Alert
+	for _, alert := range m.sessionAlerts(session) {
+		out = append(out, alert.copy())
+	}
+	return out
+}
+
+// Delete removes an alert created by the session
+func (m *AlertManager) Delete(id, session string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alert, ok := m.alerts[id]
+	if !ok || alert.session != session {
+		return fmt.Errorf("alert %q not found", id)
+	}
+	delete(m.alerts, id)
+	return nil
+}
+
+// DeleteSession removes every alert created by the session
+func (m *AlertManager) DeleteSession(session string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, alert := range m.alerts {
+		if alert.session == session {
+			delete(m.alerts, id)
+		}
+	}
+}
+
+// sessionAlerts returns a session's alerts, oldest first. The caller must
+// hold m.mu.
+func (m *AlertManager) sessionAlerts(session string) []*Alert {
+	var out []*Alert
+	for _, alert := range m.alerts {
+		if alert.session == session {
+			out = append(out, alert)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].expires.Before(out[j].expires) })
+	return out
+}
+
+// removeExpired drops alerts past their TTL. The caller must hold m.mu.
+func (m *AlertManager) removeExpired(now time.Time) {
+	for id, alert := range m.alerts {
+		if !now.Before(alert.expires) {
+			delete(m.alerts, id)
+		}
+	}
+}
+
+// copy returns a copy of the alert that shares no events with it
+func (a *Alert) copy() *Alert {
+	copied := *a
+	copied.Events = append([]AlertEvent{}, a.Events...)
+	return &copied
+}
+
+// poll checks every alert once
+func (m *AlertManager) poll() {
+	m.mu.Lock()
+	m.removeExpired(time.Now())
+	ids := make([]string, 0, len(m.alerts))
+	for id := range m.alerts {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		m.check(id)
+	}
+}
+
+// check fetches the alert's data, advances its state and notifies
+// subscribers when something changed
+func (m *AlertManager) check(id string) {
+	alert, err := m.Get(id)
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	client, notify := m.client, m.notify
+	m.mu.Unlock()
+	if client == nil {
+		return
+	}
+	reading := fetchAlertReading(client, alert)
+
+	m.mu.Lock()
+	stored, ok := m.alerts[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	changed := applyAlertReading(stored, reading, time.Now().UTC())
+	m.mu.Unlock()
+
+	if changed && notify != nil {
+		notify(stored.URI)
+	}
+}
+
+// fetchAlertReading reads the current value and, with a lookahead, the first
+// forecast hour on the triggering side of the threshold
+func fetchAlertReading(client *Client, alert *Alert) alertReading {
+	universalAqi := true
+	extra := []ExtraComputation{ExtraComputationLocalAQI, ExtraComputationPollutantConcentration}
+	current, err := client.GetCurrentConditions(CurrentConditionsRequest{
+		Location:          alert.Location,
+		ExtraComputations: extra,
+		UniversalAqi:      &universalAqi,
+	})
+	if err != nil {
+		return alertReading{err: err}
+	}
+	value, unit, ok := alert.measure(current.Indexes, current.Pollutants)
+	if !ok {
+		return alertReading{err: fmt.Errorf("%s not reported at this location", alert.metric())}
+	}
+	reading := alertReading{at: current.DateTime, value: value, units: unit}
+
+	if alert.LookaheadHours > 0 {
+		now := time.Now().UTC().Truncate(time.Hour)
+		start := now.Add(time.Hour)
+		// Background polls are not archived, or every alert would add a
+		// forecast to the archive at each poll
+		hours, err := forecastPeriod(client, alert.Location, start, start.Add(time.Duration(alert.LookaheadHours)*time.Hour), extra)
+		if err != nil {
+			return alertReading{err: err}
+		}
+		for _, h := range hours {
+			if v, _, ok := alert.measure(h.Indexes, h.Pollutants); ok && alert.crosses(v) {
+				reading.crossing = &AlertEvent{Type: "forecast", Value: v, At: h.DateTime, Source: TimeSourceForecast}
+				break
+			}
+		}
+	}
+	return reading
+}
+
+// applyAlertReading advances an alert's state and reports whether it changed
+// in a way subscribers should hear about
+func applyAlertReading(alert *Alert, reading alertReading, now time.Time) bool {
+	alert.CheckedAt = now.Format(time.RFC3339)
+	if reading.err != nil {
+		log.Printf("Alert %s check failed: %v", alert.ID, reading.err)
+		alert.LastError = reading.err.Error()
+		return false
+	}
+	alert.LastError = ""
+	value := reading.value
+	alert.Value, alert.Units = &value, reading.units
+
+	var events []AlertEvent
+	switch {
+	case alert.State != AlertTriggered && alert.crosses(value):
+		alert.State = AlertTriggered
+		events = append(events, AlertEvent{Type: "triggered", Value: value, At: reading.at, Source: TimeSourceCurrent})
+	case alert.State == AlertTriggered && alert.recovered(value):
+		alert.State = AlertNormal
+		events = append(events, AlertEvent{Type: "cleared", Value: value, At: reading.at, Source: TimeSourceCurrent})
+	case alert.State == AlertPending:
+		alert.State = AlertNormal
+	}
+
+	// Forecast crossings only matter while the alert has not triggered
+	crossing := ""
+	if reading.crossing != nil && alert.State != AlertTriggered {
+		crossing = reading.crossing.At
+	}
+	changed := crossing != alert.ForecastCrossing
+	if changed {
+		alert.ForecastCrossing = crossing
+		if reading.crossing != nil && crossing != "" {
+			events = append(events, *reading.crossing)
+		}
+	}
+
+	for i := range events {
+		events[i].Time = alert.CheckedAt
+	}
+	alert.Events = append(alert.Events, events...)
+	if len(alert.Events) > maxAlertEvents {
+		alert.Events = alert.Events[len(alert.Events)-maxAlertEvents:]
+	}
+	return changed || len(events) > 0
+}
+
+// metric describes what the alert watches
+func (a *Alert) metric() string {
+	if a.Pollutant != "" {
+		return "pollutant " + a.Pollutant
+	}
+	return "index " + a.Index
+}
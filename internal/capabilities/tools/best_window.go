@@ -0,0 +1,385 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	BestWindowToolName        = "find_best_air_quality_window"
+	BestWindowToolDescription = "Find the best upcoming time windows for outdoor activity at a location. Scans the hourly forecast and returns the top contiguous windows of the requested duration with their average, min and max values."
+)
+
+var BestWindowToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"durationHours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Length of the window in hours (default: 1)",
+		},
+		"earliestTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Earliest window start (ISO 8601, local time or an expression such as \"tomorrow\", \"next 12 hours\" or \"tomorrow morning\" whose interval also sets the end, default: now)",
+		},
+		"latestTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Latest window end (ISO 8601, local time or an expression such as \"now+6h\" or \"tomorrow 9am\", default: 24 hours after earliestTime)",
+		},
+		"index": map[string]interface{}{
+			"type":        "string",
+			"description": "Index to optimize when no pollutant is given (UAQI LOCAL, default: UAQI)",
+		},
+		"pollutant": map[string]interface{}{
+			"type":        "string",
+			"description": "Optional pollutant code to minimize instead of an index (e.g. pm25 o3 no2)",
+		},
+		"topN": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of windows to return (default: 3)",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+// BestWindowInput defines the input for the best window tool
+type BestWindowInput struct {
+	Latitude      float64 `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude     float64 `json:"longitude" jsonschema:"required,description=Location longitude"`
+	DurationHours int     `json:"durationHours,omitempty" jsonschema:"description=Length of the window in hours (default: 1)"`
+	EarliestTime  string  `json:"earliestTime,omitempty" jsonschema:"description=Earliest window start (ISO 8601 or local time)"`
+	LatestTime    string  `json:"latestTime,omitempty" jsonschema:"description=Latest window end (ISO 8601 or local time)"`
+	Index         string  `json:"index,omitempty" jsonschema:"description=Index to optimize (UAQI LOCAL)"`
+	Pollutant     string  `json:"pollutant,omitempty" jsonschema:"description=Optional pollutant code to minimize"`
+	TopN          int     `json:"topN,omitempty" jsonschema:"description=Number of windows to return (default: 3)"`
+	Units         string  `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	OutputFormat  string  `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// AirQualityWindow is a contiguous span of forecast hours
+type AirQualityWindow struct {
+	Rank      int     `json:"rank"`
+	StartTime string  `json:"startTime"`
+	EndTime   string  `json:"endTime"`
+	Average   float64 `json:"average"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+}
+
+// BestWindowOutput defines the output for the best window tool
+type BestWindowOutput struct {
+	PeriodStart    string             `json:"periodStart"`
+	PeriodEnd      string             `json:"periodEnd"`
+	Metric         string             `json:"metric"`
+	HigherIsBetter bool               `json:"higherIsBetter"`
+	Units          string             `json:"units,omitempty"`
+	HoursScanned   int                `json:"hoursScanned"`
+	Windows        []AirQualityWindow `json:"windows"`
+}
+
+// forecastMetric selects the value optimized from each hour of data
+type forecastMetric struct {
+	index     string
+	pollutant string
+}
+
+// name returns a human readable name for the metric
+func (m forecastMetric) name() string {
+	if m.pollutant != "" {
+		return m.pollutant
+	}
+	return m.index
+}
+
+// higherIsBetter reports whether larger values indicate cleaner air. Only the
+// Universal AQI counts up; local indexes and concentrations count down.
+func (m forecastMetric) higherIsBetter() bool {
+	return m.pollutant == "" && m.index == "UAQI"
+}
+
+// value extracts the metric from an hour of data
+func (m forecastMetric) value(indexes []AQI, pollutants []Pollutant) (float64, string, bool) {
+	if m.pollutant != "" {
+		for _, p := range pollutants {
+			if p.Code == m.pollutant && p.Concentration != nil {
+				return p.Concentration.Value, p.Concentration.Units, true
+			}
+		}
+		return 0, "", false
+	}
+	var idx *AQI
+	if m.index == "LOCAL" {
+		idx = localIndex(indexes)
+	} else {
+		idx = findIndex(indexes, universalAqiCode)
+	}
+	if idx == nil {
+		return 0, "", false
+	}
+	return float64(idx.Aqi), "", true
+}
+
+// NewBestWindowHandler creates a new best window handler with the API key
+func NewBestWindowHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input BestWindowInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Apply defaults
+		if input.DurationHours == 0 {
+			input.DurationHours = 1
+		}
+		if input.TopN == 0 {
+			input.TopN = 3
+		}
+		if input.Index == "" {
+			input.Index = "UAQI"
+		}
+		if input.Index != "UAQI" && input.Index != "LOCAL" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "index must be UAQI or LOCAL"}},
+			}, nil
+		}
+		if input.TopN < 1 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "topN must be at least 1"}},
+			}, nil
+		}
+		if input.DurationHours < 1 || input.DurationHours > maxForecastHours {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("durationHours must be between 1 and %d", maxForecastHours)}},
+			}, nil
+		}
+
+		// Resolve the search period, clamped to the forecast horizon
+		now := time.Now().UTC()
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		earliest, latest, err := resolvePeriodInput(input.EarliestTime, input.LatestTime, now, zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if earliest.IsZero() {
+			earliest = now
+		}
+		if latest.IsZero() {
+			latest = earliest.Add(24 * time.Hour)
+		}
+		// Windows are made of whole forecast hours, so round the period inward
+		// to keep them from starting before earliest or ending after latest
+		if rounded := earliest.Truncate(time.Hour); rounded.Before(earliest) {
+			earliest = rounded.Add(time.Hour)
+		}
+		latest = latest.Truncate(time.Hour)
+		horizonStart := now.Truncate(time.Hour).Add(time.Hour)
+		horizonEnd := now.Truncate(time.Hour).Add(maxForecastHours * time.Hour)
+		if earliest.Before(horizonStart) {
+			earliest = horizonStart
+		}
+		if latest.After(horizonEnd) {
+			latest = horizonEnd
+		}
+		if latest.Sub(earliest) < time.Duration(input.DurationHours)*time.Hour {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("the period %s to %s within the %d hour forecast horizon is shorter than the requested duration", earliest.Format(time.RFC3339), latest.Format(time.RFC3339), maxForecastHours)}},
+			}, nil
+		}
+
+		metric := forecastMetric{index: input.Index, pollutant: input.Pollutant}
+		var extra []ExtraComputation
+		if metric.pollutant != "" {
+			extra = append(extra, ExtraComputationPollutantConcentration)
+		} else if metric.index == "LOCAL" {
+			extra = append(extra, ExtraComputationLocalAQI)
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		location := LatLng{Latitude: input.Latitude, Longitude: input.Longitude}
+		hours, err := fetchForecastPeriod(client, location, earliest, latest, extra)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get forecast: %v", err)}},
+			}, nil
+		}
+
+		// Convert concentrations to the requested units
+		for i := range hours {
+			convertPollutants(hours[i].Pollutants, target)
+		}
+
+		output, err := findBestWindows(hours, metric, input.DurationHours, input.TopN)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		output.PeriodStart = earliest.Format(time.RFC3339)
+		output.PeriodEnd = latest.Format(time.RFC3339)
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
+
+// findBestWindows scores every contiguous run of duration hours and returns the
+// best topN windows that do not overlap each other
+func findBestWindows(hours []HourlyForecast, metric forecastMetric, duration, topN int) (*BestWindowOutput, error) {
+	type sample struct {
+		at    time.Time
+		value float64
+	}
+	output := &BestWindowOutput{
+		Metric:         metric.name(),
+		HigherIsBetter: metric.higherIsBetter(),
+		HoursScanned:   len(hours),
+		Windows:        []AirQualityWindow{},
+	}
+
+	var samples []sample
+	for _, h := range hours {
+		at, err := time.Parse(time.RFC3339, h.DateTime)
+		if err != nil {
+			continue
+		}
+		value, units, ok := metric.value(h.Indexes, h.Pollutants)
+		if !ok {
+			continue
+		}
+		if units != "" {
+			output.Units = units
+		}
+		samples = append(samples, sample{at: at, value: value})
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("forecast contains no %s values", metric.name())
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].at.Before(samples[j].at) })
+
+	type candidate struct {
+		start, end      int
+		avg, minV, maxV float64
+	}
+	var candidates []candidate
+	for start := 0; start+duration <= len(samples); start++ {
+		end := start + duration - 1
+		// Skip windows that span a gap in the forecast
+		if samples[end].at.Sub(samples[start].at) != time.Duration(duration-1)*time.Hour {
+			continue
+		}
+		c := candidate{start: start, end: end, minV: samples[start].value, maxV: samples[start].value}
+		sum := 0.0
+		for i := start; i <= end; i++ {
+			v := samples[i].value
+			sum += v
+			if v < c.minV {
+				c.minV = v
+			}
+			if v > c.maxV {
+				c.maxV = v
+			}
+		}
+		c.avg = sum / float64(duration)
+		candidates = append(candidates, c)
+	}
+
+	higherIsBetter := metric.higherIsBetter()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if higherIsBetter {
+			return candidates[i].avg > candidates[j].avg
+		}
+		return candidates[i].avg < candidates[j].avg
+	})
+
+	var chosen []candidate
+	for _, c := range candidates {
+		if len(chosen) == topN {
+			break
+		}
+		overlaps := false
+		for _, o := range chosen {
+			if c.start <= o.end && o.start <= c.end {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			chosen = append(chosen, c)
+		}
+	}
+
+	for i, c := range chosen {
+		output.Windows = append(output.Windows, AirQualityWindow{
+			Rank:      i + 1,
+			StartTime: samples[c.start].at.Format(time.RFC3339),
+			EndTime:   samples[c.end].at.Add(time.Hour).Format(time.RFC3339),
+			Average:   roundTo(c.avg, 2),
+			Min:       c.minV,
+			Max:       c.maxV,
+		})
+	}
+	return output, nil
+}
@@ -124,3 +124,49 @@ func (c *Client) doPostRequest(url string, reqBody interface{}, respBody interfa
 
 	return nil
 }
+
+// GetForecastHours retrieves every hourly forecast for the request by following
+// page tokens until the API reports no more pages. A repeated token is an
+// error rather than an endless loop.
+func (c *Client) GetForecastHours(req ForecastRequest) ([]HourlyForecast, error) {
+	var hours []HourlyForecast
+	seen := map[string]bool{}
+	for {
+		resp, err := c.GetForecast(req)
+		if err != nil {
+			return nil, err
+		}
+		hours = append(hours, resp.HourlyForecasts...)
+		if resp.NextPageToken == "" {
+			return hours, nil
+		}
+		if seen[resp.NextPageToken] {
+			return nil, fmt.Errorf("API returned page token %q twice", resp.NextPageToken)
+		}
+		seen[resp.NextPageToken] = true
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// GetHistoryHours retrieves every historical hour for the request by following
+// page tokens until the API reports no more pages. A repeated token is an
+// error rather than an endless loop.
+func (c *Client) GetHistoryHours(req HistoryRequest) ([]HourInfo, error) {
+	var hours []HourInfo
+	seen := map[string]bool{}
+	for {
+		resp, err := c.GetHistory(req)
+		if err != nil {
+			return nil, err
+		}
+		hours = append(hours, resp.HoursInfo...)
+		if resp.NextPageToken == "" {
+			return hours, nil
+		}
+		if seen[resp.NextPageToken] {
+			return nil, fmt.Errorf("API returned page token %q twice", resp.NextPageToken)
+		}
+		seen[resp.NextPageToken] = true
+		req.PageToken = resp.NextPageToken
+	}
+}
@@ -0,0 +1,330 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	CompareToolName        = "compare_air_quality"
+	CompareToolDescription = "Compare air quality across two or more locations at the same time (now, a forecast hour or a past hour). Returns locations ranked from cleanest to most polluted plus per-pollutant deltas."
+)
+
+var CompareToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"locations": map[string]interface{}{
+			"type":        "array",
+			"description": "Locations to compare (at least two)",
+			"minItems":    2,
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Human readable location name that is unique within the request",
+					},
+					"latitude": map[string]interface{}{
+						"type":        "number",
+						"description": "Location latitude",
+					},
+					"longitude": map[string]interface{}{
+						"type":        "number",
+						"description": "Location longitude",
+					},
+				},
+				"required": []interface{}{"name", "latitude", "longitude"},
+			},
+		},
+		"time": map[string]interface{}{
+			"type":        "string",
+			"description": "Time to compare at: \"now\" (default), an ISO 8601 or local time, or an expression such as \"now-6h\" or \"tomorrow morning\" in the past 30 days or next 96 hours",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": "Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3, default: ug/m3)",
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"locations"},
+}
+
+// NamedLocation is a location with a human readable name
+type NamedLocation struct {
+	Name      string  `json:"name" jsonschema:"required,description=Human readable location name that is unique within the request"`
+	Latitude  float64 `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude float64 `json:"longitude" jsonschema:"required,description=Location longitude"`
+}
+
+// CompareInput defines the input for the comparison tool
+type CompareInput struct {
+	Locations    []NamedLocation `json:"locations" jsonschema:"required,description=Locations to compare (at least two)"`
+	Time         string          `json:"time,omitempty" jsonschema:"description=Time to compare at: now (default) or an ISO 8601 time"`
+	Units        string          `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3, default: ug/m3)"`
+	OutputFormat string          `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// CompareIndex is a condensed air quality index value
+type CompareIndex struct {
+	Code     string `json:"code"`
+	Aqi      int    `json:"aqi"`
+	Category string `json:"category,omitempty"`
+}
+
+// CompareRanking is a single ranked location
+type CompareRanking struct {
+	Rank              int                `json:"rank"`
+	Name              string             `json:"name"`
+	DateTime          string             `json:"dateTime,omitempty"`
+	UniversalAqi      *CompareIndex      `json:"universalAqi,omitempty"`
+	LocalAqi          *CompareIndex      `json:"localAqi,omitempty"`
+	DominantPollutant string             `json:"dominantPollutant,omitempty"`
+	Pollutants        map[string]float64 `json:"pollutants,omitempty"`
+}
+
+// PollutantDelta describes how one pollutant differs across the compared locations
+type PollutantDelta struct {
+	Code  string `json:"code"`
+	Units string `json:"units"`
+	// Best and Worst name the locations with the lowest and highest concentration
+	Best  string  `json:"best"`
+	Worst string  `json:"worst"`
+	Range float64 `json:"range"`
+	// FromCleanest is each location's difference from the top-ranked location
+	FromCleanest map[string]float64 `json:"fromCleanest"`
+}
+
+// CompareOutput defines the output for the comparison tool
+type CompareOutput struct {
+	Time     string            `json:"time"`
+	Source   TimeSource        `json:"source"`
+	RankedBy string            `json:"rankedBy"`
+	Rankings []CompareRanking  `json:"rankings"`
+	Deltas   []PollutantDelta  `json:"deltas,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// NewCompareHandler creates a new comparison handler with the API key
+func NewCompareHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input CompareInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		if len(input.Locations) < 2 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "at least two locations are required"}},
+			}, nil
+		}
+		// Names identify locations in the rankings, deltas and errors
+		names := map[string]bool{}
+		for i, loc := range input.Locations {
+			if strings.TrimSpace(loc.Name) == "" {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("location %d needs a name", i+1)}},
+				}, nil
+			}
+			if names[loc.Name] {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("location name %q is used more than once, names must be unique", loc.Name)}},
+				}, nil
+			}
+			names[loc.Name] = true
+		}
+
+		// Concentrations share a unit so deltas are comparable
+		target := units.MicrogramsPerCubicMeter
+		if input.Units != "" {
+			parsed, err := units.Parse(input.Units)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				}, nil
+			}
+			target = parsed
+		}
+
+		// Resolve which endpoint serves the requested time, reading local
+		// times in the zone of the first location
+		now := time.Now().UTC()
+		zone := timezone.Lookup(input.Locations[0].Latitude, input.Locations[0].Longitude)
+		at, err := parseTimeInput(input.Time, now, zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		source, err := routeTime(at, now)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Fetch all locations concurrently
+		client := NewClient(apiKey)
+		extra := []ExtraComputation{
+			ExtraComputationLocalAQI,
+			ExtraComputationPollutantConcentration,
+			ExtraComputationDominantPollutantConcentration,
+		}
+		snapshots := make([]*hourSnapshot, len(input.Locations))
+		errs := make([]error, len(input.Locations))
+		var wg sync.WaitGroup
+		for i, loc := range input.Locations {
+			wg.Add(1)
+			go func(i int, loc NamedLocation) {
+				defer wg.Done()
+				snapshots[i], errs[i] = fetchSnapshot(client, LatLng{Latitude: loc.Latitude, Longitude: loc.Longitude}, at, source, extra)
+			}(i, loc)
+		}
+		wg.Wait()
+
+		output := compareSnapshots(input.Locations, snapshots, errs, target)
+		output.Time = at.Format(time.RFC3339)
+		output.Source = source
+
+		if len(output.Rankings) == 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get air quality for any location: %v", output.Errors)}},
+			}, nil
+		}
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
+
+// compareSnapshots ranks locations by Universal AQI (higher is cleaner) and
+// computes per-pollutant deltas against the cleanest location
+func compareSnapshots(locations []NamedLocation, snapshots []*hourSnapshot, errs []error, target units.Unit) *CompareOutput {
+	output := &CompareOutput{RankedBy: "universalAqi (higher is cleaner)"}
+
+	pollutantUnits := map[string]string{}
+	for i, loc := range locations {
+		if errs[i] != nil {
+			if output.Errors == nil {
+				output.Errors = map[string]string{}
+			}
+			output.Errors[loc.Name] = errs[i].Error()
+			continue
+		}
+		snap := snapshots[i]
+		ranking := CompareRanking{
+			Name:       loc.Name,
+			DateTime:   snap.DateTime,
+			Pollutants: map[string]float64{},
+		}
+		if idx := findIndex(snap.Indexes, universalAqiCode); idx != nil {
+			ranking.UniversalAqi = &CompareIndex{Code: idx.Code, Aqi: idx.Aqi, Category: idx.Category}
+			ranking.DominantPollutant = idx.DominantPollutant
+		}
+		if idx := localIndex(snap.Indexes); idx != nil {
+			ranking.LocalAqi = &CompareIndex{Code: idx.Code, Aqi: idx.Aqi, Category: idx.Category}
+		}
+		convertPollutants(snap.Pollutants, target)
+		for _, p := range snap.Pollutants {
+			if p.Concentration == nil {
+				continue
+			}
+			ranking.Pollutants[p.Code] = p.Concentration.Value
+			pollutantUnits[p.Code] = p.Concentration.Units
+		}
+		output.Rankings = append(output.Rankings, ranking)
+	}
+
+	sort.SliceStable(output.Rankings, func(i, j int) bool {
+		return rankingScore(output.Rankings[i]) > rankingScore(output.Rankings[j])
+	})
+	for i := range output.Rankings {
+		output.Rankings[i].Rank = i + 1
+	}
+	if len(output.Rankings) == 0 {
+		return output
+	}
+
+	cleanest := output.Rankings[0]
+	for _, code := range sortedKeys(pollutantUnits) {
+		delta := PollutantDelta{Code: code, Units: pollutantUnits[code], FromCleanest: map[string]float64{}}
+		var minValue, maxValue float64
+		first := true
+		for _, r := range output.Rankings {
+			value, ok := r.Pollutants[code]
+			if !ok {
+				continue
+			}
+			if first || value < minValue {
+				minValue, delta.Best = value, r.Name
+			}
+			if first || value > maxValue {
+				maxValue, delta.Worst = value, r.Name
+			}
+			first = false
+			if base, ok := cleanest.Pollutants[code]; ok {
+				delta.FromCleanest[r.Name] = roundTo(value-base, 2)
+			}
+		}
+		delta.Range = roundTo(maxValue-minValue, 2)
+		output.Deltas = append(output.Deltas, delta)
+	}
+
+	return output
+}
+
+// rankingScore orders rankings; locations without a Universal AQI sort last
+func rankingScore(r CompareRanking) int {
+	if r.UniversalAqi == nil {
+		return -1
+	}
+	return r.UniversalAqi.Aqi
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
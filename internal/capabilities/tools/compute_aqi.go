@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/aqi"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	ComputeAqiToolName        = "compute_aqi"
+	ComputeAqiToolDescription = "Compute air quality indexes locally from pollutant concentrations: US EPA AQI, EU CAQI, UK DAQI, India NAQI, China AQI and Canada AQHI. Uses concentrations you supply, or fetches them for a location and time. Hourly concentrations stand in for the averaging periods the indexes define (e.g. 24h PM, 8h ozone), so results are estimates."
+)
+
+var ComputeAqiToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude, to fetch concentrations when none are given",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude, to fetch concentrations when none are given",
+		},
+		"time": map[string]interface{}{
+			"type":        "string",
+			"description": "Time to fetch concentrations for: \"now\" (default), an ISO 8601 or local time, or an expression such as \"now-6h\" or \"tomorrow morning\" in the past 30 days or next 96 hours",
+		},
+		"concentrations": map[string]interface{}{
+			"type":        "array",
+			"description": "Concentrations to compute from instead of fetching them",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"pollutant": map[string]interface{}{
+						"type":        "string",
+						"description": "Pollutant code (pm25 pm10 o3 no2 so2 co nh3)",
+					},
+					"value": map[string]interface{}{
+						"type":        "number",
+						"description": "Concentration value",
+					},
+					"units": map[string]interface{}{
+						"type":        "string",
+						"description": "Concentration units (ppb ppm ug/m3 mg/m3 or API unit names, default: ug/m3)",
+					},
+				},
+				"required": []interface{}{"pollutant", "value"},
+			},
+		},
+		"indexes": map[string]interface{}{
+			"type":        "array",
+			"description": "Indexes to compute (US_EPA EU_CAQI UK_DAQI IN_NAQI CN_AQI CA_AQHI, default: all)",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+}
+
+// ConcentrationInput is a pollutant concentration supplied by the caller
+type ConcentrationInput struct {
+	Pollutant string  `json:"pollutant" jsonschema:"required,description=Pollutant code"`
+	Value     float64 `json:"value" jsonschema:"required,description=Concentration value"`
+	Units     string  `json:"units,omitempty" jsonschema:"description=Concentration units (ppb ppm ug/m3 mg/m3, default: ug/m3)"`
+}
+
+// ComputeAqiInput defines the input for the AQI computation tool
+type ComputeAqiInput struct {
+	Latitude       *float64             `json:"latitude,omitempty" jsonschema:"description=Location latitude"`
+	Longitude      *float64             `json:"longitude,omitempty" jsonschema:"description=Location longitude"`
+	Time           string               `json:"time,omitempty" jsonschema:"description=Time to fetch concentrations for: now (default) or an ISO 8601 time"`
+	Concentrations []ConcentrationInput `json:"concentrations,omitempty" jsonschema:"description=Concentrations to compute from"`
+	Indexes        []string             `json:"indexes,omitempty" jsonschema:"description=Indexes to compute (default: all)"`
+	OutputFormat   string               `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// ComputeAqiOutput defines the output for the AQI computation tool
+type ComputeAqiOutput struct {
+	DateTime       string               `json:"dateTime,omitempty"`
+	Source         TimeSource           `json:"source,omitempty"`
+	Concentrations []ConcentrationInput `json:"concentrations"`
+	Results        []*aqi.Result        `json:"results"`
+	Errors         []string             `json:"errors,omitempty"`
+}
+
+// NewComputeAqiHandler creates a new AQI computation handler with the API key
+func NewComputeAqiHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input ComputeAqiInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		standards := aqi.Standards
+		if len(input.Indexes) > 0 {
+			standards = nil
+			for _, name := range input.Indexes {
+				std, err := aqi.ParseStandard(name)
+				if err != nil {
+					return &mcp.CallToolResult{
+						IsError: true,
+						Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+					}, nil
+				}
+				standards = append(standards, std)
+			}
+		}
+
+		output := &ComputeAqiOutput{Concentrations: input.Concentrations, Results: []*aqi.Result{}}
+		var zone *time.Location
+		if len(input.Concentrations) == 0 {
+			if input.Latitude == nil || input.Longitude == nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: "either concentrations or latitude and longitude are required"}},
+				}, nil
+			}
+
+			// Resolve which endpoint serves the requested time
+			now := time.Now().UTC()
+			zone = timezone.Lookup(*input.Latitude, *input.Longitude)
+			at, err := parseTimeInput(input.Time, now, zone)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				}, nil
+			}
+			source, err := routeTime(at, now)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				}, nil
+			}
+
+			// Call API
+			client := NewClient(apiKey)
+			snap, err := fetchSnapshot(client, LatLng{Latitude: *input.Latitude, Longitude: *input.Longitude}, at, source, []ExtraComputation{
+				ExtraComputationPollutantConcentration,
+			})
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get air quality: %v", err)}},
+				}, nil
+			}
+			output.DateTime = snap.DateTime
+			output.Source = source
+			for _, p := range snap.Pollutants {
+				if p.Concentration == nil {
+					continue
+				}
+				output.Concentrations = append(output.Concentrations, ConcentrationInput{
+					Pollutant: p.Code,
+					Value:     p.Concentration.Value,
+					Units:     p.Concentration.Units,
+				})
+			}
+		}
+
+		concentrations := make(map[string]aqi.Concentration, len(output.Concentrations))
+		for _, c := range output.Concentrations {
+			unit := units.MicrogramsPerCubicMeter
+			if c.Units != "" {
+				parsed, err := units.Parse(c.Units)
+				if err != nil {
+					return &mcp.CallToolResult{
+						IsError: true,
+						Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("%s: %v", c.Pollutant, err)}},
+					}, nil
+				}
+				unit = parsed
+			}
+			concentrations[c.Pollutant] = aqi.Concentration{Value: c.Value, Units: unit}
+		}
+		for _, std := range standards {
+			result, err := aqi.Compute(std, concentrations)
+			if err != nil {
+				output.Errors = append(output.Errors, err.Error())
+				continue
+			}
+			output.Results = append(output.Results, result)
+		}
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
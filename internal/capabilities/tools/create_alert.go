@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	CreateAlertToolName        = "create_air_quality_alert"
+	CreateAlertToolDescription = "Create an alert that fires when an air quality index or pollutant concentration at a location crosses a threshold. The server checks current conditions (and optionally the forecast) in the background; subscribe to the returned airquality://alerts/{id} resource to receive notifications/resources/updated when the alert triggers, clears or a crossing is forecast."
+)
+
+var CreateAlertToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"index": map[string]interface{}{
+			"type":        "string",
+			"description": "Index to watch: UAQI, LOCAL or an index code such as usa_epa (default: UAQI unless pollutant is set)",
+		},
+		"pollutant": map[string]interface{}{
+			"type":        "string",
+			"description": "Pollutant code to watch the concentration of instead of an index (pm25 pm10 o3 no2 so2 co)",
+		},
+		"threshold": map[string]interface{}{
+			"type":        "number",
+			"description": "Value that triggers the alert",
+		},
+		"direction": map[string]interface{}{
+			"type":        "string",
+			"description": "Trigger when the value rises above or falls below the threshold (above below). Defaults to below for the Universal AQI, where lower is worse, and above for pollutants and other indexes.",
+		},
+		"hysteresis": map[string]interface{}{
+			"type":        "number",
+			"description": "How far the value must move back past the threshold before the alert clears (default: 0)",
+		},
+		"lookaheadHours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Also warn when the forecast crosses the threshold within this many hours (default: 0 max: 96)",
+		},
+	},
+	"required": []interface{}{"latitude", "longitude", "threshold"},
+}
+
+// CreateAlertInput defines the input for the create alert tool
+type CreateAlertInput struct {
+	Latitude       float64 `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude      float64 `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Index          string  `json:"index,omitempty" jsonschema:"description=Index to watch: UAQI LOCAL or an index code"`
+	Pollutant      string  `json:"pollutant,omitempty" jsonschema:"description=Pollutant code to watch instead of an index"`
+	Threshold      float64 `json:"threshold" jsonschema:"required,description=Value that triggers the alert"`
+	Direction      string  `json:"direction,omitempty" jsonschema:"description=Trigger above or below the threshold (default: below for UAQI and above otherwise)"`
+	Hysteresis     float64 `json:"hysteresis,omitempty" jsonschema:"description=How far the value must move back before the alert clears"`
+	LookaheadHours int     `json:"lookaheadHours,omitempty" jsonschema:"description=Warn when the forecast crosses the threshold within this many hours"`
+}
+
+// NewCreateAlertHandler creates a new create alert handler
+func NewCreateAlertHandler() func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input CreateAlertInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		alert := Alert{
+			Location:       LatLng{Latitude: input.Latitude, Longitude: input.Longitude},
+			Index:          input.Index,
+			Pollutant:      input.Pollutant,
+			Direction:      AlertDirection(input.Direction),
+			Threshold:      input.Threshold,
+			Hysteresis:     input.Hysteresis,
+			LookaheadHours: input.LookaheadHours,
+		}
+		if alert.Index != "" && alert.Pollutant != "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "set either index or pollutant, not both"}},
+			}, nil
+		}
+		if alert.Index == "" && alert.Pollutant == "" {
+			alert.Index = "UAQI"
+		}
+		if alert.Direction == "" {
+			// The Universal AQI falls as air gets worse
+			alert.Direction = AlertAbove
+			if strings.EqualFold(alert.Index, "UAQI") {
+				alert.Direction = AlertBelow
+			}
+		}
+		if alert.Direction != AlertAbove && alert.Direction != AlertBelow {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid direction %q, expected above or below", input.Direction)}},
+			}, nil
+		}
+		if alert.Hysteresis < 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "hysteresis must not be negative"}},
+			}, nil
+		}
+		if alert.LookaheadHours < 0 || alert.LookaheadHours > maxForecastHours {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("lookaheadHours must be between 0 and %d", maxForecastHours)}},
+			}, nil
+		}
+
+		// Store the alert and run its first check
+		created, err := Alerts.Create(alert, sessionID(request))
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to create alert: %v", err)}},
+			}, nil
+		}
+
+		// Convert response to JSON string
+		jsonResp, err := json.MarshalIndent(created, "", "  ")
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
+			}, nil
+		}
+
+		// Return success result with the alert and a link to its resource
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: string(jsonResp)},
+				&mcp.ResourceLink{
+					URI:         created.URI,
+					Name:        "Air Quality Alert " + created.ID,
+					Description: "Subscribe to be notified when the alert changes state",
+					MIMEType:    "application/json",
+				},
+			},
+		}, nil
+	}
+}
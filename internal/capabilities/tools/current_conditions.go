@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 const (
 	CurrentConditionsToolName        = "get_current_air_quality"
-	CurrentConditionsToolDescription = "Get current air quality conditions for a specific location. Returns air quality indexes, pollutant levels, and health recommendations."
+	CurrentConditionsToolDescription = "Get current air quality conditions for a specific location. Returns air quality indexes, pollutant levels, and health recommendations. With a sensitivity profile, passed in or registered with set_sensitivity_profile, returns only the recommendations that apply plus a personal risk level."
 )
 
 var CurrentConditionsToolSchema = map[string]interface{}{
@@ -43,18 +45,51 @@ var CurrentConditionsToolSchema = map[string]interface{}{
 			"type":        "string",
 			"description": "Response language code (default: en)",
 		},
+		"nowcast": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Also compute US EPA NowCast values for PM2.5, PM10 and ozone from the last hours of history (default: false)",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"profile": profileSchema,
+		"fields": map[string]interface{}{
+			"type":        "array",
+			"description": fieldsDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": pollutantsFilterDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
 	},
 	"required": []interface{}{"latitude", "longitude"},
 }
 
 // CurrentConditionsInput defines the input for the current conditions tool
 type CurrentConditionsInput struct {
-	Latitude          float64  `json:"latitude" jsonschema:"required,description=Location latitude"`
-	Longitude         float64  `json:"longitude" jsonschema:"required,description=Location longitude"`
-	ExtraComputations []string `json:"extraComputations,omitempty" jsonschema:"description=Additional features to compute (LOCAL_AQI HEALTH_RECOMMENDATIONS POLLUTANT_ADDITIONAL_INFO DOMINANT_POLLUTANT_CONCENTRATION POLLUTANT_CONCENTRATION)"`
-	UaqiColorPalette  string   `json:"uaqiColorPalette,omitempty" jsonschema:"description=Color palette for UAQI (RED_GREEN INDIGO_PERSIAN NUMERIC)"`
-	UniversalAqi      *bool    `json:"universalAqi,omitempty" jsonschema:"description=Include Universal AQI (default: true)"`
-	LanguageCode      string   `json:"languageCode,omitempty" jsonschema:"description=Response language code (default: en)"`
+	Latitude          float64             `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude         float64             `json:"longitude" jsonschema:"required,description=Location longitude"`
+	ExtraComputations []string            `json:"extraComputations,omitempty" jsonschema:"description=Additional features to compute (LOCAL_AQI HEALTH_RECOMMENDATIONS POLLUTANT_ADDITIONAL_INFO DOMINANT_POLLUTANT_CONCENTRATION POLLUTANT_CONCENTRATION)"`
+	UaqiColorPalette  string              `json:"uaqiColorPalette,omitempty" jsonschema:"description=Color palette for UAQI (RED_GREEN INDIGO_PERSIAN NUMERIC)"`
+	UniversalAqi      *bool               `json:"universalAqi,omitempty" jsonschema:"description=Include Universal AQI (default: true)"`
+	LanguageCode      string              `json:"languageCode,omitempty" jsonschema:"description=Response language code (default: en)"`
+	NowCast           bool                `json:"nowcast,omitempty" jsonschema:"description=Also compute US EPA NowCast values (default: false)"`
+	Units             string              `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	Profile           *SensitivityProfile `json:"profile,omitempty" jsonschema:"description=Sensitivity profile (default: the stored profile)"`
+	Fields            []string            `json:"fields,omitempty" jsonschema:"description=Parts of the response to keep"`
+	Pollutants        []string            `json:"pollutants,omitempty" jsonschema:"description=Pollutant and index codes to keep"`
+	OutputFormat      string              `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
 }
 
 // CurrentConditionsOutput defines the output for the current conditions tool
@@ -84,6 +119,14 @@ func NewCurrentConditionsHandler(apiKey string) func(ctx context.Context, reques
 			}
 		}
 
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
 		// Build request
 		req := CurrentConditionsRequest{
 			Location: LatLng{
@@ -104,28 +147,86 @@ func NewCurrentConditionsHandler(apiKey string) func(ctx context.Context, reques
 			req.UaqiColorPalette = ColorPalette(input.UaqiColorPalette)
 		}
 
-		// Call API
-		client := NewClient(apiKey)
-		resp, err := client.GetCurrentConditions(req)
+		target, err := parseUnitsInput(input.Units)
 		if err != nil {
 			return &mcp.CallToolResult{
 				IsError: true,
-				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get current conditions: %v", err)}},
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
 			}, nil
 		}
 
-		// Convert response to JSON string
-		jsonResp, err := json.MarshalIndent(resp, "", "  ")
+		projection, err := ParseProjection(input.Fields, input.Pollutants)
 		if err != nil {
 			return &mcp.CallToolResult{
 				IsError: true,
-				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
 			}, nil
 		}
+		if projection.NeedsConcentrations() {
+			req.ExtraComputations = appendMissing(req.ExtraComputations, ExtraComputationPollutantConcentration)
+		}
+
+		profile, err := resolveProfile(request, input.Profile)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Personal guidance needs recommendations and concentrations
+		if profile != nil {
+			req.ExtraComputations = appendMissing(req.ExtraComputations,
+				ExtraComputationHealthRecommendations,
+				ExtraComputationPollutantConcentration,
+			)
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		resp, err := client.GetCurrentConditions(req)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get current conditions: %v", err)}},
+			}, nil
+		}
+
+		// Attach NowCast values when requested
+		output := &CurrentConditionsWithNowCast{CurrentConditionsResponse: resp}
+		if input.NowCast {
+			output.NowCast, err = computeNowCast(client, req.Location, resp, nil)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compute NowCast: %v", err)}},
+				}, nil
+			}
+		}
+
+		// Replace the recommendations with ones tailored to the profile. The
+		// conditions are still returned when the guidance can't be computed,
+		// e.g. when no concentrations are reported.
+		if profile != nil {
+			guidance, err := personalGuidance(*profile, resp.Pollutants, resp.HealthRecommendations)
+			if err != nil {
+				output.PersonalGuidanceNote = fmt.Sprintf("personal guidance unavailable: %v", err)
+			} else {
+				output.PersonalGuidance = guidance
+				resp.HealthRecommendations = nil
+			}
+		}
+
+		// Convert concentrations to the requested units
+		convertPollutants(resp.Pollutants, target)
+		if output.NowCast != nil {
+			convertNowCast(output.NowCast, target)
+		}
+
+		// Drop the parts of the response that were not asked for
+		output.CurrentConditionsResponse = projection.Current(resp)
 
-		// Return success result with JSON response
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: string(jsonResp)}},
-		}, nil
+		// Render response in the requested format
+		return renderResult(output, outputFormat, timezone.Lookup(input.Latitude, input.Longitude)), nil
 	}
 }
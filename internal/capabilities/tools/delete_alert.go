@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	DeleteAlertToolName        = "delete_air_quality_alert"
+	DeleteAlertToolDescription = "Delete an alert created with create_air_quality_alert in this session so it is no longer checked."
+)
+
+var DeleteAlertToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"id": map[string]interface{}{
+			"type":        "string",
+			"description": "ID of the alert to delete",
+		},
+	},
+	"required": []interface{}{"id"},
+}
+
+// DeleteAlertInput defines the input for the delete alert tool
+type DeleteAlertInput struct {
+	ID string `json:"id" jsonschema:"required,description=ID of the alert to delete"`
+}
+
+// DeleteAlertOutput defines the output for the delete alert tool
+type DeleteAlertOutput struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
+
+// NewDeleteAlertHandler creates a new delete alert handler
+func NewDeleteAlertHandler() func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input DeleteAlertInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		if err := Alerts.Delete(input.ID, sessionID(request)); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Convert response to JSON string
+		jsonResp, err := json.MarshalIndent(DeleteAlertOutput{ID: input.ID, Deleted: true}, "", "  ")
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
+			}, nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: string(jsonResp)}},
+		}, nil
+	}
+}
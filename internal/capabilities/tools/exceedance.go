@@ -0,0 +1,342 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	ExceedanceToolName        = "check_air_quality_standards"
+	ExceedanceToolDescription = "Check whether a location breached air quality standards over a past period. Evaluates historical concentrations against WHO 2021, EU, US NAAQS or India NAAQS limits using each limit's averaging period (1h, 8h rolling, 24h) and reports exceedance counts and the worst episodes per pollutant."
+)
+
+var ExceedanceToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"standard": map[string]interface{}{
+			"type":        "string",
+			"description": "Standard to evaluate against (WHO_2021 EU US_NAAQS IN_NAAQS, default: WHO_2021)",
+		},
+		"hours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of past hours to evaluate (default: 24 max: 720)",
+		},
+		"periodStartTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period start time (ISO 8601, local time or an expression such as \"yesterday\", \"last 3 days\" or \"last night\" whose interval also sets the end), instead of hours",
+		},
+		"periodEndTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period end time (ISO 8601, local time or an expression such as \"now-6h\" or \"yesterday 18:00\", default: now)",
+		},
+		"maxEpisodes": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of worst episodes to return per limit (default: 3)",
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+const (
+	// minHoursPer8h is the number of valid hours an 8-hour mean needs (75%)
+	minHoursPer8h = 6
+	// minHoursPer24h is the number of valid hours a daily mean needs (75%)
+	minHoursPer24h = 18
+)
+
+// ExceedanceInput defines the input for the standards exceedance tool
+type ExceedanceInput struct {
+	Latitude        float64 `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude       float64 `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Standard        string  `json:"standard,omitempty" jsonschema:"description=Standard to evaluate against (WHO_2021 EU US_NAAQS IN_NAAQS)"`
+	Hours           int     `json:"hours,omitempty" jsonschema:"description=Number of past hours to evaluate (default: 24 max: 720)"`
+	PeriodStartTime string  `json:"periodStartTime,omitempty" jsonschema:"description=Period start time (ISO 8601 or local time)"`
+	PeriodEndTime   string  `json:"periodEndTime,omitempty" jsonschema:"description=Period end time (ISO 8601 or local time)"`
+	MaxEpisodes     int     `json:"maxEpisodes,omitempty" jsonschema:"description=Number of worst episodes to return per limit (default: 3)"`
+	OutputFormat    string  `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// ExceedanceEpisode is a run of consecutive averaging periods above a limit
+type ExceedanceEpisode struct {
+	StartTime string  `json:"startTime"`
+	EndTime   string  `json:"endTime"`
+	Periods   int     `json:"periods"`
+	Peak      float64 `json:"peak"`
+	PeakTime  string  `json:"peakTime"`
+}
+
+// LimitEvaluation reports how a pollutant compared with one limit.
+// Exceedances are counted in the same unit as AllowedPerYear: hours for
+// 1-hour limits, and for 8-hour and 24-hour limits the local days whose
+// maximum 8-hour mean or daily mean is above the limit.
+type LimitEvaluation struct {
+	StandardLimit
+	PeriodsEvaluated int                 `json:"periodsEvaluated"`
+	Exceedances      int                 `json:"exceedances"`
+	ExceedanceUnit   string              `json:"exceedanceUnit"`
+	Max              float64             `json:"max"`
+	MaxTime          string              `json:"maxTime"`
+	Episodes         int                 `json:"episodes"`
+	WorstEpisodes    []ExceedanceEpisode `json:"worstEpisodes,omitempty"`
+}
+
+// ExceedanceOutput defines the output for the standards exceedance tool
+type ExceedanceOutput struct {
+	Standard       string            `json:"standard"`
+	StandardName   string            `json:"standardName"`
+	PeriodStart    string            `json:"periodStart"`
+	PeriodEnd      string            `json:"periodEnd"`
+	ExceededLimits int               `json:"exceededLimits"`
+	Evaluations    []LimitEvaluation `json:"evaluations"`
+	NotEvaluated   []string          `json:"notEvaluated,omitempty"`
+}
+
+// averagedValue is a concentration averaged over [Start, End). At labels the
+// period: the last hour for rolling means, the day start for daily means.
+type averagedValue struct {
+	At    time.Time
+	Start time.Time
+	End   time.Time
+	Value float64
+}
+
+// NewExceedanceHandler creates a new standards exceedance handler with the API key
+func NewExceedanceHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input ExceedanceInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		if input.Standard == "" {
+			input.Standard = "WHO_2021"
+		}
+		standard, err := ParseStandard(input.Standard)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if input.MaxEpisodes <= 0 {
+			input.MaxEpisodes = 3
+		}
+
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		start, end, err := resolveHistoryPeriod(input.Hours, input.PeriodStartTime, input.PeriodEndTime, time.Now().UTC(), zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		hours, err := fetchHistoryPeriod(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, start, end, []ExtraComputation{
+			ExtraComputationPollutantConcentration,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get history: %v", err)}},
+			}, nil
+		}
+
+		output := &ExceedanceOutput{
+			Standard:     standard.Code,
+			StandardName: standard.Name,
+			PeriodStart:  start.Format(time.RFC3339),
+			PeriodEnd:    end.Format(time.RFC3339),
+			Evaluations:  []LimitEvaluation{},
+		}
+		series, seriesUnits := pollutantSeries(historySnapshots(hours))
+		for _, limit := range standard.Limits {
+			evaluation, err := evaluateLimit(limit, series[limit.Pollutant], units.Unit(seriesUnits[limit.Pollutant]), input.MaxEpisodes, zone)
+			if err != nil {
+				output.NotEvaluated = append(output.NotEvaluated, fmt.Sprintf("%s %s: %v", limit.Pollutant, limit.Averaging, err))
+				continue
+			}
+			if evaluation.Exceedances > 0 {
+				output.ExceededLimits++
+			}
+			output.Evaluations = append(output.Evaluations, *evaluation)
+		}
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
+
+// evaluateLimit averages a pollutant series over the limit's averaging period
+// and collects the periods and episodes above the limit. Days are calendar
+// days in loc.
+func evaluateLimit(limit StandardLimit, points []seriesPoint, from units.Unit, maxEpisodes int, loc *time.Location) (*LimitEvaluation, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no data")
+	}
+	converted := make([]seriesPoint, len(points))
+	for i, p := range points {
+		v, err := units.ConvertAt(limit.Pollutant, p.Value, from, limit.Units, units.StandardConditions)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = seriesPoint{At: p.At, Value: v}
+	}
+	values := averageSeries(converted, limit.Averaging, loc)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("not enough hours for a %s mean", limit.Averaging)
+	}
+
+	evaluation := &LimitEvaluation{StandardLimit: limit, PeriodsEvaluated: len(values), ExceedanceUnit: "hours"}
+	if limit.Averaging != Averaging1h {
+		evaluation.ExceedanceUnit = "days"
+	}
+	exceedanceDays := map[time.Time]bool{}
+	var episodes []ExceedanceEpisode
+	var current *ExceedanceEpisode
+	var last time.Time
+	for i, v := range values {
+		if i == 0 || v.Value > evaluation.Max {
+			evaluation.Max, evaluation.MaxTime = roundTo(v.Value, 2), v.At.UTC().Format(time.RFC3339)
+		}
+		if v.Value <= limit.Value {
+			current = nil
+			continue
+		}
+		switch limit.Averaging {
+		case Averaging1h:
+			evaluation.Exceedances++
+		default:
+			// A day counts once however many of its means are above the
+			// limit, that is when its maximum 8-hour mean is
+			exceedanceDays[localDay(v.At, loc)] = true
+		}
+		// Periods continue an episode when they follow on from the last one:
+		// the next hour, or for daily means the next day
+		follows := v.At.Sub(last) == time.Hour
+		if limit.Averaging == Averaging24h {
+			follows = v.Start.Equal(last)
+		}
+		if current == nil || !follows {
+			episodes = append(episodes, ExceedanceEpisode{StartTime: v.Start.UTC().Format(time.RFC3339)})
+			current = &episodes[len(episodes)-1]
+		}
+		current.EndTime = v.End.UTC().Format(time.RFC3339)
+		current.Periods++
+		if peak := roundTo(v.Value, 2); current.PeakTime == "" || peak > current.Peak {
+			current.Peak, current.PeakTime = peak, v.At.UTC().Format(time.RFC3339)
+		}
+		last = v.At
+		if limit.Averaging == Averaging24h {
+			last = v.End
+		}
+	}
+	if limit.Averaging != Averaging1h {
+		evaluation.Exceedances = len(exceedanceDays)
+	}
+
+	evaluation.Episodes = len(episodes)
+	sort.SliceStable(episodes, func(i, j int) bool { return episodes[i].Peak > episodes[j].Peak })
+	if len(episodes) > maxEpisodes {
+		episodes = episodes[:maxEpisodes]
+	}
+	evaluation.WorstEpisodes = episodes
+	return evaluation, nil
+}
+
+// averageSeries averages a time-ordered hourly series over an averaging period.
+// Rolling and daily means need 75% of their hours to be present. Daily means
+// cover calendar days in loc.
+func averageSeries(points []seriesPoint, period AveragingPeriod, loc *time.Location) []averagedValue {
+	var values []averagedValue
+	switch period {
+	case Averaging8h:
+		byHour := make(map[time.Time]float64, len(points))
+		for _, p := range points {
+			byHour[p.At] = p.Value
+		}
+		for _, p := range points {
+			var window []float64
+			for h := 0; h < 8; h++ {
+				if v, ok := byHour[p.At.Add(-time.Duration(h)*time.Hour)]; ok {
+					window = append(window, v)
+				}
+			}
+			if len(window) >= minHoursPer8h {
+				values = append(values, averagedValue{At: p.At, Start: p.At.Add(-7 * time.Hour), End: p.At.Add(time.Hour), Value: mean(window)})
+			}
+		}
+	case Averaging24h:
+		var day time.Time
+		var window []float64
+		flush := func() {
+			if len(window) >= minHoursPer24h {
+				values = append(values, averagedValue{At: day, Start: day, End: day.AddDate(0, 0, 1), Value: mean(window)})
+			}
+			window = window[:0]
+		}
+		for _, p := range points {
+			d := localDay(p.At, loc)
+			if !d.Equal(day) {
+				flush()
+				day = d
+			}
+			window = append(window, p.Value)
+		}
+		flush()
+	default:
+		for _, p := range points {
+			values = append(values, averagedValue{At: p.At, Start: p.At, End: p.At.Add(time.Hour), Value: p.Value})
+		}
+	}
+	return values
+}
+
+// localDay returns the start of the calendar day in loc that t falls on
+func localDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/export"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	ExportToolName        = "export_air_quality"
+	ExportToolDescription = "Export current conditions, forecast or history for a location as CSV, NDJSON, GeoJSON or XLSX, flattened to one row per hour per pollutant with UAQI and local index values. Returns the file as an embedded resource and a download URL that expires."
+)
+
+// ExportURIPrefix is the URI prefix of export resources
+const ExportURIPrefix = "airquality://exports/"
+
+var ExportToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"dataset": map[string]interface{}{
+			"type":        "string",
+			"description": "Data to export (current forecast history)",
+		},
+		"format": map[string]interface{}{
+			"type":        "string",
+			"description": "File format (csv ndjson geojson xlsx, default: csv)",
+		},
+		"hours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of hours to export: upcoming for forecast (default: 24 max: 96), past for history (default: 24 max: 720)",
+		},
+		"periodStartTime": map[string]interface{}{
+			"type":        "string",
+			"description": "History period start time (ISO 8601, local time or an expression such as \"yesterday\", \"last 3 days\" or \"last night\" whose interval also sets the end), instead of hours",
+		},
+		"periodEndTime": map[string]interface{}{
+			"type":        "string",
+			"description": "History period end time (ISO 8601, local time or an expression such as \"now-6h\" or \"yesterday 18:00\", default: now)",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude", "dataset"},
+}
+
+// Exports holds exported files until they expire
+var Exports = export.NewStore(time.Hour, 100, 256<<20)
+
+// ExportInput defines the input for the export tool
+type ExportInput struct {
+	Latitude        float64 `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude       float64 `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Dataset         string  `json:"dataset" jsonschema:"required,description=Data to export (current forecast history)"`
+	Format          string  `json:"format,omitempty" jsonschema:"description=File format (csv ndjson geojson xlsx)"`
+	Hours           int     `json:"hours,omitempty" jsonschema:"description=Number of hours to export"`
+	PeriodStartTime string  `json:"periodStartTime,omitempty" jsonschema:"description=History period start time (ISO 8601 or local time)"`
+	PeriodEndTime   string  `json:"periodEndTime,omitempty" jsonschema:"description=History period end time (ISO 8601 or local time)"`
+	Units           string  `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+}
+
+// ExportOutput defines the output for the export tool
+type ExportOutput struct {
+	ID          string `json:"id"`
+	Format      string `json:"format"`
+	Rows        int    `json:"rows"`
+	Bytes       int    `json:"bytes"`
+	ResourceURI string `json:"resourceUri"`
+	DownloadURL string `json:"downloadUrl"`
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+// NewExportHandler creates a new export handler with the API key. Download
+// URLs are made absolute with baseURL when it is set.
+func NewExportHandler(apiKey, baseURL string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input ExportInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		if input.Format == "" {
+			input.Format = string(export.FormatCSV)
+		}
+		format, err := export.ParseFormat(input.Format)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		location := LatLng{Latitude: input.Latitude, Longitude: input.Longitude}
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		snapshots, source, err := fetchExportSnapshots(client, location, zone, input)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		for i := range snapshots {
+			convertPollutants(snapshots[i].Pollutants, target)
+		}
+
+		rows := exportRows(location, zone, source, snapshots)
+		data, err := export.Encode(format, rows)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to encode export: %v", err)}},
+			}, nil
+		}
+		filename := fmt.Sprintf("air-quality-%s-%s.%s", source, time.Now().UTC().Format("20060102T150405Z"), format)
+		stored, err := Exports.Put(filename, format, data)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to store export: %v", err)}},
+			}, nil
+		}
+
+		output := &ExportOutput{
+			ID:          stored.ID,
+			Format:      string(format),
+			Rows:        len(rows),
+			Bytes:       len(data),
+			ResourceURI: ExportURIPrefix + stored.ID,
+			DownloadURL: strings.TrimSuffix(baseURL, "/") + "/exports/" + stored.ID,
+			ExpiresAt:   stored.ExpiresAt.Format(time.RFC3339),
+		}
+
+		// Convert response to JSON string
+		jsonResp, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
+			}, nil
+		}
+
+		// Return the export details and the file as an embedded resource
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: string(jsonResp)},
+				&mcp.EmbeddedResource{Resource: ExportContents(stored)},
+			},
+		}, nil
+	}
+}
+
+// ExportContents returns an export as resource contents, as text for text
+// formats and as a blob for binary ones
+func ExportContents(e *export.Export) *mcp.ResourceContents {
+	contents := &mcp.ResourceContents{URI: ExportURIPrefix + e.ID, MIMEType: e.Format.MIMEType()}
+	if e.Format.Binary() {
+		contents.Blob = e.Data
+	} else {
+		contents.Text = string(e.Data)
+	}
+	return contents
+}
+
+// fetchExportSnapshots fetches the hours of the requested dataset. History
+// period times without a UTC offset are read in zone.
+func fetchExportSnapshots(client *Client, location LatLng, zone *time.Location, input ExportInput) ([]hourSnapshot, TimeSource, error) {
+	universalAqi := true
+	extra := []ExtraComputation{ExtraComputationLocalAQI, ExtraComputationPollutantConcentration}
+	switch TimeSource(input.Dataset) {
+	case TimeSourceCurrent:
+		resp, err := client.GetCurrentConditions(CurrentConditionsRequest{
+			Location:          location,
+			ExtraComputations: extra,
+			UniversalAqi:      &universalAqi,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed to get current conditions: %v", err)
+		}
+		return []hourSnapshot{{DateTime: resp.DateTime, Indexes: resp.Indexes, Pollutants: resp.Pollutants}}, TimeSourceCurrent, nil
+	case TimeSourceForecast:
+		if input.Hours == 0 {
+			input.Hours = 24
+		}
+		if input.Hours < 1 || input.Hours > maxForecastHours {
+			return nil, "", fmt.Errorf("hours must be between 1 and %d for forecasts", maxForecastHours)
+		}
+		start := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+		hours, err := fetchForecastPeriod(client, location, start, start.Add(time.Duration(input.Hours)*time.Hour), extra)
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed to get forecast: %v", err)
+		}
+		snapshots := make([]hourSnapshot, 0, len(hours))
+		for _, h := range hours {
+			snapshots = append(snapshots, hourSnapshot{DateTime: h.DateTime, Indexes: h.Indexes, Pollutants: h.Pollutants})
+		}
+		return snapshots, TimeSourceForecast, nil
+	case TimeSourceHistory:
+		start, end, err := resolveHistoryPeriod(input.Hours, input.PeriodStartTime, input.PeriodEndTime, time.Now().UTC(), zone)
+		if err != nil {
+			return nil, "", err
+		}
+		hours, err := fetchHistoryPeriod(client, location, start, end, extra)
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed to get history: %v", err)
+		}
+		return historySnapshots(hours), TimeSourceHistory, nil
+	default:
+		return nil, "", fmt.Errorf("invalid dataset %q, expected current, forecast or history", input.Dataset)
+	}
+}
+
+// exportRows flattens snapshots to one row per hour per pollutant, with
+// local times in zone
+func exportRows(location LatLng, zone *time.Location, source TimeSource, snapshots []hourSnapshot) []export.Row {
+	var rows []export.Row
+	for _, s := range snapshots {
+		base := export.Row{
+			Time:      s.DateTime,
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+			Source:    string(source),
+		}
+		if t, err := time.Parse(time.RFC3339, s.DateTime); err == nil {
+			base.LocalTime = t.In(zone).Format(time.RFC3339)
+		}
+		if idx := findIndex(s.Indexes, universalAqiCode); idx != nil {
+			value := idx.Aqi
+			base.Uaqi, base.UaqiCategory, base.DominantPollutant = &value, idx.Category, idx.DominantPollutant
+		}
+		if idx := localIndex(s.Indexes); idx != nil {
+			value := idx.Aqi
+			base.LocalAqiCode, base.LocalAqi = idx.Code, &value
+		}
+
+		added := false
+		for _, p := range s.Pollutants {
+			if p.Concentration == nil {
+				continue
+			}
+			row := base
+			value := p.Concentration.Value
+			row.Pollutant, row.Concentration, row.Units = p.Code, &value, p.Concentration.Units
+			rows = append(rows, row)
+			added = true
+		}
+		if !added {
+			rows = append(rows, base)
+		}
+	}
+	return rows
+}
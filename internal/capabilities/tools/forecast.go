@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -53,15 +56,37 @@ var ForecastToolSchema = map[string]interface{}{
 		},
 		"dateTime": map[string]interface{}{
 			"type":        "string",
-			"description": "Specific forecast time (ISO 8601 format)",
+			"description": "Specific forecast time (ISO 8601, local time or an expression such as \"now+6h\" or \"tomorrow 9am\")",
 		},
 		"periodStartTime": map[string]interface{}{
 			"type":        "string",
-			"description": "Forecast period start time (ISO 8601 format)",
+			"description": "Forecast period start time (ISO 8601, local time or an expression such as \"tomorrow\", \"next 12 hours\" or \"tomorrow morning\" whose interval also sets the end)",
 		},
 		"periodEndTime": map[string]interface{}{
 			"type":        "string",
-			"description": "Forecast period end time (ISO 8601 format)",
+			"description": "Forecast period end time (ISO 8601, local time or an expression such as \"now+6h\" or \"tomorrow 9am\")",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"fields": map[string]interface{}{
+			"type":        "array",
+			"description": fieldsDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": pollutantsFilterDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
 		},
 	},
 	"required": []interface{}{"latitude", "longitude"},
@@ -77,9 +102,13 @@ type ForecastInput struct {
 	UaqiColorPalette  string   `json:"uaqiColorPalette,omitempty" jsonschema:"description=Color palette for UAQI"`
 	UniversalAqi      *bool    `json:"universalAqi,omitempty" jsonschema:"description=Include Universal AQI (default: true)"`
 	LanguageCode      string   `json:"languageCode,omitempty" jsonschema:"description=Response language code (default: en)"`
-	DateTime          string   `json:"dateTime,omitempty" jsonschema:"description=Specific forecast time (ISO 8601 format)"`
-	PeriodStartTime   string   `json:"periodStartTime,omitempty" jsonschema:"description=Forecast period start time (ISO 8601 format)"`
-	PeriodEndTime     string   `json:"periodEndTime,omitempty" jsonschema:"description=Forecast period end time (ISO 8601 format)"`
+	DateTime          string   `json:"dateTime,omitempty" jsonschema:"description=Specific forecast time (ISO 8601 or local time)"`
+	PeriodStartTime   string   `json:"periodStartTime,omitempty" jsonschema:"description=Forecast period start time (ISO 8601 or local time)"`
+	PeriodEndTime     string   `json:"periodEndTime,omitempty" jsonschema:"description=Forecast period end time (ISO 8601 or local time)"`
+	Units             string   `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	Fields            []string `json:"fields,omitempty" jsonschema:"description=Parts of the response to keep"`
+	Pollutants        []string `json:"pollutants,omitempty" jsonschema:"description=Pollutant and index codes to keep"`
+	OutputFormat      string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
 }
 
 // ForecastOutput defines the output for the forecast tool
@@ -109,6 +138,29 @@ func NewForecastHandler(apiKey string) func(ctx context.Context, request *mcp.Ca
 			}
 		}
 
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Resolve times in the location's time zone; forecast periods start no
+		// earlier than the next hour
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		now := time.Now().UTC()
+		resolved, err := resolveTimeInputs(input.DateTime, input.PeriodStartTime, input.PeriodEndTime, now, now.Truncate(time.Hour).Add(time.Hour), time.Time{}, zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if resolved != nil {
+			input.DateTime, input.PeriodStartTime, input.PeriodEndTime = resolved.DateTime, resolved.PeriodStartTime, resolved.PeriodEndTime
+		}
+
 		// Build request
 		req := ForecastRequest{
 			Location: LatLng{
@@ -140,9 +192,28 @@ func NewForecastHandler(apiKey string) func(ctx context.Context, request *mcp.Ca
 			}
 		}
 
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		projection, err := ParseProjection(input.Fields, input.Pollutants)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if projection.NeedsConcentrations() {
+			req.ExtraComputations = appendMissing(req.ExtraComputations, ExtraComputationPollutantConcentration)
+		}
+
 		// Call API
 		client := NewClient(apiKey)
-		resp, err := client.GetForecast(req)
+		resp, err := FetchForecast(client, req)
 		if err != nil {
 			return &mcp.CallToolResult{
 				IsError: true,
@@ -150,18 +221,22 @@ func NewForecastHandler(apiKey string) func(ctx context.Context, request *mcp.Ca
 			}, nil
 		}
 
-		// Convert response to JSON string
-		jsonResp, err := json.MarshalIndent(resp, "", "  ")
+		// Convert concentrations to the requested units
+		for i := range resp.HourlyForecasts {
+			convertPollutants(resp.HourlyForecasts[i].Pollutants, target)
+		}
+
+		// Drop the parts of the response that were not asked for
+		resp = projection.Forecast(resp)
+
+		// Echo the resolved times and render response in the requested format
+		output, err := withResolvedTime(resp, resolved)
 		if err != nil {
 			return &mcp.CallToolResult{
 				IsError: true,
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
 			}, nil
 		}
-
-		// Return success result with JSON response
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: string(jsonResp)}},
-		}, nil
+		return renderResult(output, outputFormat, zone), nil
 	}
 }
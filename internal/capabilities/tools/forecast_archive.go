@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"log"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/forecasts"
+)
+
+// forecastArchive records the forecasts fetched for callers so they can later
+// be verified against history; nil disables archiving
+var forecastArchive *forecasts.Store
+
+// FetchForecast retrieves a forecast and archives it in the units the API
+// returned. Tools and resources fetch forecasts through it, or through
+// fetchForecastPeriod, so forecast_skill sees every forecast they serve.
+func FetchForecast(client *Client, req ForecastRequest) (*ForecastResponse, error) {
+	resp, err := client.GetForecast(req)
+	if err != nil {
+		return nil, err
+	}
+	archiveForecast(req.Location, resp.HourlyForecasts)
+	return resp, nil
+}
+
+// archiveForecast stores the index values, categories and concentrations of
+// fetched forecast hours
+func archiveForecast(location LatLng, hours []HourlyForecast) {
+	if forecastArchive == nil {
+		return
+	}
+	record := forecasts.Record{
+		IssuedAt:  time.Now().UTC(),
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+	}
+	for _, h := range hours {
+		at, err := time.Parse(time.RFC3339, h.DateTime)
+		if err != nil {
+			continue
+		}
+		hour := forecasts.Hour{
+			Time:       at.UTC(),
+			Values:     map[string]float64{},
+			Units:      map[string]string{},
+			Categories: map[string]string{},
+		}
+		for _, idx := range h.Indexes {
+			hour.Values[idx.Code] = float64(idx.Aqi)
+			hour.Categories[idx.Code] = idx.Category
+		}
+		for _, p := range h.Pollutants {
+			if p.Concentration != nil {
+				hour.Values[p.Code] = p.Concentration.Value
+				hour.Units[p.Code] = p.Concentration.Units
+			}
+		}
+		if len(hour.Values) > 0 {
+			record.Hours = append(record.Hours, hour)
+		}
+	}
+	if len(record.Hours) == 0 {
+		return
+	}
+	if err := forecastArchive.Append(record); err != nil {
+		log.Printf("Failed to archive forecast: %v", err)
+	}
+}
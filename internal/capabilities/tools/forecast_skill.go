@@ -0,0 +1,315 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/aqi"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	ForecastSkillToolName        = "forecast_skill"
+	ForecastSkillToolDescription = "Report how reliable past forecasts were for a location. Matches the forecasts this server has archived (every forecast fetched by tools and resources while archiving is enabled, apart from background alert checks) against observed history for the same hours and reports bias, MAE, RMSE and category hit rate per lead time and per pollutant or index. Only locations whose forecasts were fetched before have data."
+)
+
+var ForecastSkillToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude, as used when the forecasts were fetched",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude, as used when the forecasts were fetched",
+		},
+		"hours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of past hours of forecast targets to verify (default: 168 max: 720)",
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": "Pollutant or index codes to verify (default: all archived)",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+// leadTimeBucket groups forecast hours by how far ahead they were issued
+type leadTimeBucket struct {
+	min, max int
+}
+
+// leadTimeBuckets are the lead time groups skill is reported for
+var leadTimeBuckets = []leadTimeBucket{{0, 6}, {7, 12}, {13, 24}, {25, 48}, {49, 72}, {73, maxForecastHours}}
+
+// ForecastSkillInput defines the input for the forecast skill tool
+type ForecastSkillInput struct {
+	Latitude     float64  `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude    float64  `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Hours        int      `json:"hours,omitempty" jsonschema:"description=Number of past hours of forecast targets to verify (default: 168 max: 720)"`
+	Pollutants   []string `json:"pollutants,omitempty" jsonschema:"description=Pollutant or index codes to verify"`
+	OutputFormat string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// LeadTimeSkill is the forecast error for one lead time group
+type LeadTimeSkill struct {
+	LeadHours       string   `json:"leadHours"`
+	Count           int      `json:"count"`
+	Bias            float64  `json:"bias"`
+	MAE             float64  `json:"mae"`
+	RMSE            float64  `json:"rmse"`
+	CategoryPairs   int      `json:"categoryPairs"`
+	CategoryHitRate *float64 `json:"categoryHitRate,omitempty"`
+}
+
+// CodeSkill is the forecast skill for one pollutant or index
+type CodeSkill struct {
+	Code      string          `json:"code"`
+	Units     string          `json:"units,omitempty"`
+	LeadTimes []LeadTimeSkill `json:"leadTimes"`
+}
+
+// ForecastSkillOutput defines the output for the forecast skill tool
+type ForecastSkillOutput struct {
+	PeriodStart   string      `json:"periodStart"`
+	PeriodEnd     string      `json:"periodEnd"`
+	ForecastsUsed int         `json:"forecastsUsed"`
+	MatchedPairs  int         `json:"matchedPairs"`
+	Skill         []CodeSkill `json:"skill"`
+}
+
+// forecastPair is a forecast value and the value observed for the same hour
+type forecastPair struct {
+	lead                int
+	forecast, observed  float64
+	forecastCat, obsCat string
+}
+
+// NewForecastSkillHandler creates a new forecast skill handler with the API key
+func NewForecastSkillHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input ForecastSkillInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		if forecastArchive == nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "forecast archiving is disabled, set FORECAST_STORE_DIR to enable it"}},
+			}, nil
+		}
+		if input.Hours == 0 {
+			input.Hours = 168
+		}
+		start, end, err := resolveHistoryPeriod(input.Hours, "", "", time.Now().UTC(), nil)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Forecasts targeting the period were issued up to maxForecastHours before it
+		records, err := forecastArchive.Load(input.Latitude, input.Longitude, start.Add(-maxForecastHours*time.Hour))
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to read archived forecasts: %v", err)}},
+			}, nil
+		}
+		if len(records) == 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "no archived forecasts for this location, fetch forecasts for it first and check back once their hours have passed"}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		hours, err := fetchHistoryPeriod(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, start, end, []ExtraComputation{
+			ExtraComputationLocalAQI,
+			ExtraComputationPollutantConcentration,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get history: %v", err)}},
+			}, nil
+		}
+
+		// Observed values and categories per hour and code
+		observed := map[time.Time]map[string]float64{}
+		observedCats := map[time.Time]map[string]string{}
+		observedUnits := map[string]string{}
+		for _, h := range hours {
+			at, err := time.Parse(time.RFC3339, h.DateTime)
+			if err != nil {
+				continue
+			}
+			at = at.UTC()
+			observed[at], observedCats[at] = map[string]float64{}, map[string]string{}
+			for _, idx := range h.Indexes {
+				observed[at][idx.Code] = float64(idx.Aqi)
+				observedCats[at][idx.Code] = idx.Category
+			}
+			for _, p := range h.Pollutants {
+				if p.Concentration != nil {
+					observed[at][p.Code] = p.Concentration.Value
+					observedUnits[p.Code] = p.Concentration.Units
+					observedCats[at][p.Code] = pollutantCategory(p.Code, p.Concentration.Value, p.Concentration.Units)
+				}
+			}
+		}
+
+		// Pair forecasts with observations, keeping the latest forecast per
+		// issue hour so repeated fetches don't count twice
+		type pairKey struct {
+			issued, target time.Time
+			code           string
+		}
+		pairs := map[pairKey]forecastPair{}
+		used := map[time.Time]bool{}
+		for _, r := range records {
+			issued := r.IssuedAt.UTC().Truncate(time.Hour)
+			for _, h := range r.Hours {
+				if h.Time.Before(start) || !h.Time.Before(end) {
+					continue
+				}
+				obs, ok := observed[h.Time]
+				if !ok {
+					continue
+				}
+				for code, v := range h.Values {
+					o, ok := obs[code]
+					if !ok || (len(input.Pollutants) > 0 && !slices.Contains(input.Pollutants, code)) {
+						continue
+					}
+					cat := h.Categories[code]
+					if cat == "" && h.Units[code] != "" {
+						cat = pollutantCategory(code, v, h.Units[code])
+					}
+					pairs[pairKey{issued, h.Time, code}] = forecastPair{
+						lead:        int(h.Time.Sub(issued) / time.Hour),
+						forecast:    v,
+						observed:    o,
+						forecastCat: cat,
+						obsCat:      observedCats[h.Time][code],
+					}
+					used[issued] = true
+				}
+			}
+		}
+
+		byCode := map[string][]forecastPair{}
+		for k, p := range pairs {
+			byCode[k.code] = append(byCode[k.code], p)
+		}
+		output := &ForecastSkillOutput{
+			PeriodStart:   start.Format(time.RFC3339),
+			PeriodEnd:     end.Format(time.RFC3339),
+			ForecastsUsed: len(used),
+			MatchedPairs:  len(pairs),
+			Skill:         []CodeSkill{},
+		}
+		for _, code := range sortedKeys(byCode) {
+			output.Skill = append(output.Skill, CodeSkill{
+				Code:      code,
+				Units:     observedUnits[code],
+				LeadTimes: leadTimeSkill(byCode[code]),
+			})
+		}
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, timezone.Lookup(input.Latitude, input.Longitude)), nil
+	}
+}
+
+// leadTimeSkill computes error statistics per lead time bucket
+func leadTimeSkill(pairs []forecastPair) []LeadTimeSkill {
+	out := []LeadTimeSkill{}
+	for _, b := range leadTimeBuckets {
+		var sum, absSum, sqSum float64
+		skill := LeadTimeSkill{LeadHours: fmt.Sprintf("%d-%d", b.min, b.max)}
+		hits := 0
+		for _, p := range pairs {
+			if p.lead < b.min || p.lead > b.max {
+				continue
+			}
+			d := p.forecast - p.observed
+			sum += d
+			absSum += math.Abs(d)
+			sqSum += d * d
+			skill.Count++
+			if p.forecastCat != "" && p.obsCat != "" {
+				skill.CategoryPairs++
+				if p.forecastCat == p.obsCat {
+					hits++
+				}
+			}
+		}
+		if skill.Count == 0 {
+			continue
+		}
+		n := float64(skill.Count)
+		skill.Bias = roundTo(sum/n, 3)
+		skill.MAE = roundTo(absSum/n, 3)
+		skill.RMSE = roundTo(math.Sqrt(sqSum/n), 3)
+		if skill.CategoryPairs > 0 {
+			rate := roundTo(float64(hits)/float64(skill.CategoryPairs), 3)
+			skill.CategoryHitRate = &rate
+		}
+		out = append(out, skill)
+	}
+	return out
+}
+
+// pollutantCategory returns the US EPA AQI category of a concentration, or ""
+// for pollutants the AQI does not cover
+func pollutantCategory(code string, value float64, unit string) string {
+	result, err := aqi.Compute(aqi.StandardUSEPA, map[string]aqi.Concentration{
+		code: {Value: value, Units: units.Unit(unit)},
+	})
+	if err != nil {
+		return ""
+	}
+	return result.Category
+}
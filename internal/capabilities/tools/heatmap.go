@@ -1,17 +1,18 @@
 package tools
 
 import (
+	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"image/png"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 const (
 	HeatmapToolName        = "get_air_quality_heatmap_tile"
-	HeatmapToolDescription = "Get air quality heatmap tile image for visualization. Returns a PNG image tile for the specified map type and coordinates."
+	HeatmapToolDescription = "Get air quality heatmap tile image for visualization. Returns a PNG (or JPEG) image tile for the specified map type and either tile coordinates or a latitude/longitude, together with the tile's bounding box."
 )
 
 var HeatmapToolSchema = map[string]interface{}{
@@ -27,27 +28,54 @@ var HeatmapToolSchema = map[string]interface{}{
 		},
 		"x": map[string]interface{}{
 			"type":        "integer",
-			"description": "East-west tile coordinate",
+			"description": "East-west tile coordinate (required unless latitude and longitude are given)",
 		},
 		"y": map[string]interface{}{
 			"type":        "integer",
-			"description": "North-south tile coordinate",
+			"description": "North-south tile coordinate (required unless latitude and longitude are given)",
+		},
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Latitude of a point to fetch the covering tile for, instead of x and y",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Longitude of a point to fetch the covering tile for, instead of x and y",
+		},
+		"format": map[string]interface{}{
+			"type":        "string",
+			"description": "Image format (png jpeg, default: png). webp falls back to png",
+		},
+		"size": map[string]interface{}{
+			"type":        "integer",
+			"description": "Downscale the 256px tile to this many pixels per side",
+		},
+		"quality": map[string]interface{}{
+			"type":        "integer",
+			"description": "JPEG quality 1-100 (default: 80)",
 		},
 	},
-	"required": []interface{}{"mapType", "zoom", "x", "y"},
+	"required": []interface{}{"mapType", "zoom"},
 }
 
 // HeatmapInput defines the input for the heatmap tile tool
 type HeatmapInput struct {
 	MapType string `json:"mapType" jsonschema:"required,description=Type of heatmap (UAQI_RED_GREEN UAQI_INDIGO_PERSIAN PM25_INDIGO_PERSIAN GBR_DEFRA DEU_UBA CAN_EC FRA_ATMO US_AQI)"`
 	Zoom    int    `json:"zoom" jsonschema:"required,description=Zoom level (0-16)"`
-	X       int    `json:"x" jsonschema:"required,description=East-west tile coordinate"`
-	Y       int    `json:"y" jsonschema:"required,description=North-south tile coordinate"`
+	X       *int   `json:"x,omitempty" jsonschema:"description=East-west tile coordinate"`
+	Y       *int   `json:"y,omitempty" jsonschema:"description=North-south tile coordinate"`
+	// Latitude and Longitude select the tile covering a point instead of X and Y
+	Latitude  *float64 `json:"latitude,omitempty" jsonschema:"description=Latitude of a point to fetch the covering tile for"`
+	Longitude *float64 `json:"longitude,omitempty" jsonschema:"description=Longitude of a point to fetch the covering tile for"`
+	Format    string   `json:"format,omitempty" jsonschema:"description=Image format (png jpeg, default: png)"`
+	Size      int      `json:"size,omitempty" jsonschema:"description=Downscale the 256px tile to this many pixels per side"`
+	Quality   int      `json:"quality,omitempty" jsonschema:"description=JPEG quality 1-100 (default: 80)"`
 }
 
 // HeatmapOutput defines the output for the heatmap tile tool
 type HeatmapOutput struct {
-	ImageData string `json:"imageData" jsonschema:"description=Base64 encoded PNG image data"`
+	ImageData []byte `json:"imageData" jsonschema:"description=Encoded image data"`
+	MIMEType  string `json:"mimeType" jsonschema:"description=MIME type of the image data"`
 }
 
 // NewHeatmapHandler creates a new heatmap handler with the API key
@@ -73,16 +101,59 @@ func NewHeatmapHandler(apiKey string) func(ctx context.Context, request *mcp.Cal
 		}
 
 		// Validate zoom level
-		if input.Zoom < 0 || input.Zoom > 16 {
+		if input.Zoom < 0 || input.Zoom > MaxHeatmapZoom {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("zoom must be between 0 and %d", MaxHeatmapZoom)}},
+			}, nil
+		}
+
+		// Select the tile by its coordinates or by a point it covers
+		hasTile := input.X != nil || input.Y != nil
+		hasPoint := input.Latitude != nil || input.Longitude != nil
+		if hasTile == hasPoint {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "provide either x and y or latitude and longitude"}},
+			}, nil
+		}
+		var x, y int
+		var point *TilePixel
+		if hasPoint {
+			if input.Latitude == nil || input.Longitude == nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: "latitude and longitude must be provided together"}},
+				}, nil
+			}
+			if err := ValidateLatLng(*input.Latitude, *input.Longitude); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				}, nil
+			}
+			var pixel TilePixel
+			x, y, pixel = LatLngToTile(*input.Latitude, *input.Longitude, input.Zoom)
+			point = &pixel
+		} else {
+			if input.X == nil || input.Y == nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: "x and y must be provided together"}},
+				}, nil
+			}
+			x, y = *input.X, *input.Y
+		}
+		if err := ValidateTile(input.Zoom, x, y); err != nil {
 			return &mcp.CallToolResult{
 				IsError: true,
-				Content: []mcp.Content{&mcp.TextContent{Text: "zoom must be between 0 and 16"}},
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
 			}, nil
 		}
 
 		// Call API
 		client := NewClient(apiKey)
-		imageData, err := client.GetHeatmapTile(MapType(input.MapType), input.Zoom, input.X, input.Y)
+		imageData, err := client.GetHeatmapTile(MapType(input.MapType), input.Zoom, x, y)
 		if err != nil {
 			return &mcp.CallToolResult{
 				IsError: true,
@@ -90,13 +161,54 @@ func NewHeatmapHandler(apiKey string) func(ctx context.Context, request *mcp.Cal
 			}, nil
 		}
 
-		// Encode image as base64
-		base64Data := base64.StdEncoding.EncodeToString(imageData)
-		result := fmt.Sprintf("data:image/png;base64,%s", base64Data)
+		// Re-encode only when a different format or size was requested
+		output := HeatmapOutput{ImageData: imageData, MIMEType: "image/png"}
+		format := ImageFormat(input.Format)
+		if (format != "" && format != ImageFormatPNG) || (input.Size > 0 && input.Size < TileSize) {
+			tile, err := png.Decode(bytes.NewReader(imageData))
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to decode heatmap tile: %v", err)}},
+				}, nil
+			}
+			output.ImageData, output.MIMEType, _, err = encodeImage(downscaleImage(tile, input.Size), format, input.Quality)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				}, nil
+			}
+		}
+
+		// Describe the tile so callers can place it on a map
+		info := HeatmapTileInfo{
+			MapType: input.MapType,
+			Zoom:    input.Zoom,
+			X:       x,
+			Y:       y,
+			Bounds:  TileBoundsFor(input.Zoom, x, y),
+			Point:   point,
+		}
+		jsonInfo, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal tile info: %v", err)}},
+			}, nil
+		}
 
-		// Return success result with base64 image
+		// Return success result with the image, a link to the full tile resource and tile info
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+			Content: []mcp.Content{
+				&mcp.ImageContent{MIMEType: output.MIMEType, Data: output.ImageData},
+				&mcp.ResourceLink{
+					URI:      fmt.Sprintf("airquality://heatmap/%s/%d/%d/%d", input.MapType, input.Zoom, x, y),
+					Name:     fmt.Sprintf("%s heatmap tile %d/%d/%d", input.MapType, input.Zoom, x, y),
+					MIMEType: "image/png",
+				},
+				&mcp.TextContent{Text: string(jsonInfo)},
+			},
 		}, nil
 	}
 }
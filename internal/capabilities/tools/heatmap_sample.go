@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	HeatmapSampleToolName        = "sample_heatmap_at_points"
+	HeatmapSampleToolDescription = "Estimate air quality index values at many points by reading heatmap tile colors instead of making a current conditions lookup per point. Returns an estimated value, category and confidence for each point."
+)
+
+const (
+	// defaultSampleZoom balances spatial detail against the number of tiles fetched
+	defaultSampleZoom = 10
+	// maxSamplePoints caps how many points a single call may sample
+	maxSamplePoints = 100
+	// highConfidenceDistance and mediumConfidenceDistance are RGB distances
+	// below which a pixel is considered a close palette match
+	highConfidenceDistance   = 20
+	mediumConfidenceDistance = 60
+)
+
+var HeatmapSampleToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"mapType": map[string]interface{}{
+			"type":        "string",
+			"description": "Type of heatmap (UAQI_RED_GREEN UAQI_INDIGO_PERSIAN PM25_INDIGO_PERSIAN GBR_DEFRA DEU_UBA CAN_EC FRA_ATMO US_AQI)",
+		},
+		"zoom": map[string]interface{}{
+			"type":        "integer",
+			"description": "Zoom level (0-16, default: 10). Points on the same tile share one tile fetch",
+		},
+		"points": map[string]interface{}{
+			"type":        "array",
+			"description": "Points to sample (max 100)",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional label for the point",
+					},
+					"latitude": map[string]interface{}{
+						"type":        "number",
+						"description": "Point latitude",
+					},
+					"longitude": map[string]interface{}{
+						"type":        "number",
+						"description": "Point longitude",
+					},
+				},
+				"required": []interface{}{"latitude", "longitude"},
+			},
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"mapType", "points"},
+}
+
+// SamplePoint is a point to sample on the heatmap
+type SamplePoint struct {
+	Name      string  `json:"name,omitempty" jsonschema:"description=Optional label for the point"`
+	Latitude  float64 `json:"latitude" jsonschema:"required,description=Point latitude"`
+	Longitude float64 `json:"longitude" jsonschema:"required,description=Point longitude"`
+}
+
+// HeatmapSampleInput defines the input for the heatmap sampling tool
+type HeatmapSampleInput struct {
+	MapType      string        `json:"mapType" jsonschema:"required,description=Type of heatmap"`
+	Zoom         *int          `json:"zoom,omitempty" jsonschema:"description=Zoom level (0-16, default: 10)"`
+	Points       []SamplePoint `json:"points" jsonschema:"required,description=Points to sample (max 100)"`
+	OutputFormat string        `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// HeatmapSample is the estimated value at one point
+type HeatmapSample struct {
+	Name       string   `json:"name,omitempty"`
+	Latitude   float64  `json:"latitude"`
+	Longitude  float64  `json:"longitude"`
+	Value      *float64 `json:"value,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	Color      string   `json:"color,omitempty"`
+	Confidence string   `json:"confidence"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// HeatmapSampleOutput defines the output for the heatmap sampling tool
+type HeatmapSampleOutput struct {
+	MapType      MapType         `json:"mapType"`
+	Index        string          `json:"index"`
+	Units        string          `json:"units,omitempty"`
+	Zoom         int             `json:"zoom"`
+	TilesFetched int             `json:"tilesFetched"`
+	Samples      []HeatmapSample `json:"samples"`
+	Note         string          `json:"note"`
+}
+
+// heatmapSampleNote explains the limits of reading values back from colors
+const heatmapSampleNote = "Values are estimated by matching rendered tile colors against an approximation of the map type's palette. Tiles are smoothed and blended, so treat values as indicative; use get_current_air_quality for authoritative readings. Confidence reflects how closely the pixel matched the palette."
+
+// NewHeatmapSampleHandler creates a new heatmap sampling handler with the API key
+func NewHeatmapSampleHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input HeatmapSampleInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		mapType, err := ParseMapType(input.MapType)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if len(input.Points) == 0 || len(input.Points) > maxSamplePoints {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("between 1 and %d points are required", maxSamplePoints)}},
+			}, nil
+		}
+		zoom := defaultSampleZoom
+		if input.Zoom != nil {
+			zoom = *input.Zoom
+		}
+		if zoom < 0 || zoom > MaxHeatmapZoom {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("zoom must be between 0 and %d", MaxHeatmapZoom)}},
+			}, nil
+		}
+
+		sampler := newHeatmapSampler(NewClient(apiKey), mapType, zoom)
+		palette := Palettes[mapType]
+		output := HeatmapSampleOutput{
+			MapType: mapType,
+			Index:   palette.Index,
+			Units:   palette.Units,
+			Zoom:    zoom,
+			Note:    heatmapSampleNote,
+		}
+		for _, p := range input.Points {
+			output.Samples = append(output.Samples, sampler.sample(p))
+		}
+		output.TilesFetched = len(sampler.tiles)
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, nil), nil
+	}
+}
+
+// heatmapSampler reads palette values from heatmap tiles, fetching each tile once
+type heatmapSampler struct {
+	client  *Client
+	mapType MapType
+	zoom    int
+	tiles   map[[2]int]image.Image
+	errs    map[[2]int]error
+}
+
+func newHeatmapSampler(client *Client, mapType MapType, zoom int) *heatmapSampler {
+	return &heatmapSampler{
+		client:  client,
+		mapType: mapType,
+		zoom:    zoom,
+		tiles:   map[[2]int]image.Image{},
+		errs:    map[[2]int]error{},
+	}
+}
+
+// tile returns the decoded tile, fetching it on first use
+func (s *heatmapSampler) tile(x, y int) (image.Image, error) {
+	key := [2]int{x, y}
+	if img, ok := s.tiles[key]; ok {
+		return img, nil
+	}
+	if err, ok := s.errs[key]; ok {
+		return nil, err
+	}
+	data, err := s.client.GetHeatmapTile(s.mapType, s.zoom, x, y)
+	if err == nil {
+		var img image.Image
+		if img, err = png.Decode(bytes.NewReader(data)); err == nil {
+			s.tiles[key] = img
+			return img, nil
+		}
+	}
+	s.errs[key] = err
+	return nil, err
+}
+
+// sample estimates the palette value at a point
+func (s *heatmapSampler) sample(p SamplePoint) HeatmapSample {
+	result := HeatmapSample{Name: p.Name, Latitude: p.Latitude, Longitude: p.Longitude, Confidence: "none"}
+	if err := ValidateLatLng(p.Latitude, p.Longitude); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	x, y, pixel := LatLngToTile(p.Latitude, p.Longitude, s.zoom)
+	img, err := s.tile(x, y)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to get heatmap tile: %v", err)
+		return result
+	}
+
+	bounds := img.Bounds()
+	c := color.RGBAModel.Convert(img.At(bounds.Min.X+pixel.X, bounds.Min.Y+pixel.Y)).(color.RGBA)
+	if c.A == 0 {
+		result.Error = "no data at this point (transparent pixel)"
+		return result
+	}
+	// Un-premultiply partially transparent pixels before matching
+	if c.A < 255 {
+		c = color.RGBA{
+			R: uint8(uint16(c.R) * 255 / uint16(c.A)),
+			G: uint8(uint16(c.G) * 255 / uint16(c.A)),
+			B: uint8(uint16(c.B) * 255 / uint16(c.A)),
+			A: 255,
+		}
+	}
+
+	value, category, distance := Palettes[s.mapType].Lookup(c)
+	value = roundTo(value, 1)
+	result.Value = &value
+	result.Category = category
+	result.Color = fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	switch {
+	case distance < highConfidenceDistance:
+		result.Confidence = "high"
+	case distance < mediumConfidenceDistance:
+		result.Confidence = "medium"
+	default:
+		result.Confidence = "low"
+	}
+	return result
+}
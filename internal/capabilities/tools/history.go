@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -53,7 +56,7 @@ var HistoryToolSchema = map[string]interface{}{
 		},
 		"dateTime": map[string]interface{}{
 			"type":        "string",
-			"description": "Specific historical time (ISO 8601 format)",
+			"description": "Specific historical time (ISO 8601, local time or an expression such as \"now-6h\" or \"yesterday 18:00\")",
 		},
 		"hours": map[string]interface{}{
 			"type":        "integer",
@@ -61,11 +64,33 @@ var HistoryToolSchema = map[string]interface{}{
 		},
 		"periodStartTime": map[string]interface{}{
 			"type":        "string",
-			"description": "History period start time (ISO 8601 format)",
+			"description": "History period start time (ISO 8601, local time or an expression such as \"yesterday\", \"last 3 days\" or \"last night\" whose interval also sets the end)",
 		},
 		"periodEndTime": map[string]interface{}{
 			"type":        "string",
-			"description": "History period end time (ISO 8601 format)",
+			"description": "History period end time (ISO 8601, local time or an expression such as \"now-6h\" or \"yesterday 18:00\")",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"fields": map[string]interface{}{
+			"type":        "array",
+			"description": fieldsDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": pollutantsFilterDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
 		},
 	},
 	"required": []interface{}{"latitude", "longitude"},
@@ -81,10 +106,14 @@ type HistoryInput struct {
 	UaqiColorPalette  string   `json:"uaqiColorPalette,omitempty" jsonschema:"description=Color palette for UAQI"`
 	UniversalAqi      *bool    `json:"universalAqi,omitempty" jsonschema:"description=Include Universal AQI (default: true)"`
 	LanguageCode      string   `json:"languageCode,omitempty" jsonschema:"description=Response language code (default: en)"`
-	DateTime          string   `json:"dateTime,omitempty" jsonschema:"description=Specific historical time (ISO 8601 format)"`
+	DateTime          string   `json:"dateTime,omitempty" jsonschema:"description=Specific historical time (ISO 8601 or local time)"`
 	Hours             int      `json:"hours,omitempty" jsonschema:"description=Number of hours of history"`
-	PeriodStartTime   string   `json:"periodStartTime,omitempty" jsonschema:"description=History period start time (ISO 8601 format)"`
-	PeriodEndTime     string   `json:"periodEndTime,omitempty" jsonschema:"description=History period end time (ISO 8601 format)"`
+	PeriodStartTime   string   `json:"periodStartTime,omitempty" jsonschema:"description=History period start time (ISO 8601 or local time)"`
+	PeriodEndTime     string   `json:"periodEndTime,omitempty" jsonschema:"description=History period end time (ISO 8601 or local time)"`
+	Units             string   `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	Fields            []string `json:"fields,omitempty" jsonschema:"description=Parts of the response to keep"`
+	Pollutants        []string `json:"pollutants,omitempty" jsonschema:"description=Pollutant and index codes to keep"`
+	OutputFormat      string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
 }
 
 // HistoryOutput defines the output for the history tool
@@ -114,6 +143,29 @@ func NewHistoryHandler(apiKey string) func(ctx context.Context, request *mcp.Cal
 			}
 		}
 
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Resolve times in the location's time zone; history periods end no later
+		// than the current hour
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		now := time.Now().UTC()
+		resolved, err := resolveTimeInputs(input.DateTime, input.PeriodStartTime, input.PeriodEndTime, now, time.Time{}, now.Truncate(time.Hour), zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if resolved != nil {
+			input.DateTime, input.PeriodStartTime, input.PeriodEndTime = resolved.DateTime, resolved.PeriodStartTime, resolved.PeriodEndTime
+		}
+
 		// Build request
 		req := HistoryRequest{
 			Location: LatLng{
@@ -146,6 +198,25 @@ func NewHistoryHandler(apiKey string) func(ctx context.Context, request *mcp.Cal
 			}
 		}
 
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		projection, err := ParseProjection(input.Fields, input.Pollutants)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if projection.NeedsConcentrations() {
+			req.ExtraComputations = appendMissing(req.ExtraComputations, ExtraComputationPollutantConcentration)
+		}
+
 		// Call API
 		client := NewClient(apiKey)
 		resp, err := client.GetHistory(req)
@@ -156,18 +227,22 @@ func NewHistoryHandler(apiKey string) func(ctx context.Context, request *mcp.Cal
 			}, nil
 		}
 
-		// Convert response to JSON string
-		jsonResp, err := json.MarshalIndent(resp, "", "  ")
+		// Convert concentrations to the requested units
+		for i := range resp.HoursInfo {
+			convertPollutants(resp.HoursInfo[i].Pollutants, target)
+		}
+
+		// Drop the parts of the response that were not asked for
+		resp = projection.History(resp)
+
+		// Echo the resolved times and render response in the requested format
+		output, err := withResolvedTime(resp, resolved)
 		if err != nil {
 			return &mcp.CallToolResult{
 				IsError: true,
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
 			}, nil
 		}
-
-		// Return success result with JSON response
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: string(jsonResp)}},
-		}, nil
+		return renderResult(output, outputFormat, zone), nil
 	}
 }
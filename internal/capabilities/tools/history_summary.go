@@ -0,0 +1,274 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	HistorySummaryToolName        = "summarize_air_quality_history"
+	HistorySummaryToolDescription = "Summarize historical air quality for a location over a period. Returns per-pollutant and per-index statistics (mean, min, max, percentiles), daily aggregates over local days, hours spent in each category, dominant pollutant frequency and missing-hour counts instead of raw hourly records."
+)
+
+var HistorySummaryToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"hours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of past hours to summarize (default: 24 max: 720)",
+		},
+		"periodStartTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period start time (ISO 8601, local time or an expression such as \"yesterday\", \"last 3 days\" or \"last night\" whose interval also sets the end), instead of hours",
+		},
+		"periodEndTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period end time (ISO 8601, local time or an expression such as \"now-6h\" or \"yesterday 18:00\", default: now)",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+// HistorySummaryInput defines the input for the history summary tool
+type HistorySummaryInput struct {
+	Latitude        float64 `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude       float64 `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Hours           int     `json:"hours,omitempty" jsonschema:"description=Number of past hours to summarize (default: 24 max: 720)"`
+	PeriodStartTime string  `json:"periodStartTime,omitempty" jsonschema:"description=Period start time (ISO 8601 or local time)"`
+	PeriodEndTime   string  `json:"periodEndTime,omitempty" jsonschema:"description=Period end time (ISO 8601 or local time)"`
+	Units           string  `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	OutputFormat    string  `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// DailyStats summarizes one local day of values
+type DailyStats struct {
+	Date  string  `json:"date"`
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+// IndexSummary summarizes one air quality index over the period
+type IndexSummary struct {
+	Code          string         `json:"code"`
+	DisplayName   string         `json:"displayName,omitempty"`
+	Stats         SummaryStats   `json:"stats"`
+	CategoryHours map[string]int `json:"categoryHours"`
+	Daily         []DailyStats   `json:"daily"`
+}
+
+// PollutantSummary summarizes one pollutant over the period
+type PollutantSummary struct {
+	Code         string       `json:"code"`
+	Units        string       `json:"units"`
+	Stats        SummaryStats `json:"stats"`
+	MissingHours int          `json:"missingHours"`
+	Daily        []DailyStats `json:"daily"`
+}
+
+// HistorySummaryOutput defines the output for the history summary tool
+type HistorySummaryOutput struct {
+	PeriodStart        string             `json:"periodStart"`
+	PeriodEnd          string             `json:"periodEnd"`
+	ExpectedHours      int                `json:"expectedHours"`
+	ReturnedHours      int                `json:"returnedHours"`
+	MissingHours       int                `json:"missingHours"`
+	DominantPollutants map[string]int     `json:"dominantPollutants,omitempty"`
+	Indexes            []IndexSummary     `json:"indexes"`
+	Pollutants         []PollutantSummary `json:"pollutants"`
+}
+
+// NewHistorySummaryHandler creates a new history summary handler with the API key
+func NewHistorySummaryHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input HistorySummaryInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		start, end, err := resolveHistoryPeriod(input.Hours, input.PeriodStartTime, input.PeriodEndTime, time.Now().UTC(), zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		hours, err := fetchHistoryPeriod(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, start, end, []ExtraComputation{
+			ExtraComputationLocalAQI,
+			ExtraComputationPollutantConcentration,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get history: %v", err)}},
+			}, nil
+		}
+
+		// Convert concentrations to the requested units
+		for i := range hours {
+			convertPollutants(hours[i].Pollutants, target)
+		}
+
+		output := summarizeHistory(historySnapshots(hours), start, end, zone)
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
+
+// summarizeHistory aggregates hourly snapshots over [start, end), with daily
+// statistics over calendar days in loc
+func summarizeHistory(hours []hourSnapshot, start, end time.Time, loc *time.Location) *HistorySummaryOutput {
+	output := &HistorySummaryOutput{
+		PeriodStart:        start.Format(time.RFC3339),
+		PeriodEnd:          end.Format(time.RFC3339),
+		ExpectedHours:      int(end.Sub(start) / time.Hour),
+		DominantPollutants: map[string]int{},
+		Indexes:            []IndexSummary{},
+		Pollutants:         []PollutantSummary{},
+	}
+
+	// Count distinct hours actually returned
+	seen := map[string]bool{}
+	for _, h := range hours {
+		seen[h.DateTime] = true
+	}
+	output.ReturnedHours = len(seen)
+	output.MissingHours = max(0, output.ExpectedHours-output.ReturnedHours)
+
+	// Index statistics, categories and dominant pollutants
+	displayNames := map[string]string{}
+	categories := map[string]map[string]int{}
+	for _, h := range hours {
+		for _, idx := range h.Indexes {
+			displayNames[idx.Code] = idx.DisplayName
+			if categories[idx.Code] == nil {
+				categories[idx.Code] = map[string]int{}
+			}
+			if idx.Category != "" {
+				categories[idx.Code][idx.Category]++
+			}
+			if idx.Code == universalAqiCode && idx.DominantPollutant != "" {
+				output.DominantPollutants[idx.DominantPollutant]++
+			}
+		}
+	}
+	indexes := indexSeries(hours)
+	for _, code := range sortedKeys(indexes) {
+		output.Indexes = append(output.Indexes, IndexSummary{
+			Code:          code,
+			DisplayName:   displayNames[code],
+			Stats:         summarize(seriesValues(indexes[code])),
+			CategoryHours: categories[code],
+			Daily:         dailyStats(indexes[code], loc),
+		})
+	}
+
+	// Pollutant statistics
+	pollutants, units := pollutantSeries(hours)
+	for _, code := range sortedKeys(pollutants) {
+		output.Pollutants = append(output.Pollutants, PollutantSummary{
+			Code:         code,
+			Units:        units[code],
+			Stats:        summarize(seriesValues(pollutants[code])),
+			MissingHours: max(0, output.ExpectedHours-len(pollutants[code])),
+			Daily:        dailyStats(pollutants[code], loc),
+		})
+	}
+
+	return output
+}
+
+// dailyStats groups a time-ordered series by calendar day in loc, so days
+// match the exceedance and trend tools
+func dailyStats(points []seriesPoint, loc *time.Location) []DailyStats {
+	var days []DailyStats
+	var values []float64
+	flush := func(date string) {
+		if len(values) == 0 {
+			return
+		}
+		s := summarize(values)
+		days = append(days, DailyStats{Date: date, Count: s.Count, Mean: s.Mean, Min: s.Min, Max: s.Max})
+		values = values[:0]
+	}
+	current := ""
+	for _, p := range points {
+		date := localDay(p.At, loc).Format(time.DateOnly)
+		if date != current {
+			flush(current)
+			current = date
+		}
+		values = append(values, p.Value)
+	}
+	flush(current)
+	return days
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
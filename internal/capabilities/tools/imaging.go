@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// ImageFormat is an output encoding for rendered images
+type ImageFormat string
+
+const (
+	ImageFormatPNG  ImageFormat = "png"
+	ImageFormatJPEG ImageFormat = "jpeg"
+	// ImageFormatWebP is accepted but falls back to PNG because the standard
+	// library has no WebP encoder
+	ImageFormatWebP ImageFormat = "webp"
+)
+
+// defaultJPEGQuality is used when no JPEG quality is requested
+const defaultJPEGQuality = 80
+
+// encodeImage encodes img in the requested format and returns the encoded bytes,
+// their MIME type and the format actually used
+func encodeImage(img image.Image, format ImageFormat, quality int) ([]byte, string, ImageFormat, error) {
+	var buf bytes.Buffer
+	switch format {
+	case "", ImageFormatPNG, ImageFormatWebP:
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, "", "", fmt.Errorf("failed to encode png: %w", err)
+		}
+		return buf.Bytes(), "image/png", ImageFormatPNG, nil
+	case ImageFormatJPEG:
+		if quality <= 0 || quality > 100 {
+			quality = defaultJPEGQuality
+		}
+		// JPEG has no alpha channel, so flatten transparent areas onto white
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+		if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, "", "", fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", ImageFormatJPEG, nil
+	default:
+		return nil, "", "", fmt.Errorf("unsupported image format %q (png jpeg webp)", format)
+	}
+}
+
+// downscaleImage shrinks img so its longest side is at most maxSize pixels by
+// averaging the source pixels covered by each output pixel. Images that already
+// fit are returned unchanged.
+func downscaleImage(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if maxSize <= 0 || (w <= maxSize && h <= maxSize) {
+		return img
+	}
+
+	outW, outH := maxSize, maxSize
+	if w > h {
+		outH = max(1, h*maxSize/w)
+	} else {
+		outW = max(1, w*maxSize/h)
+	}
+
+	out := image.NewNRGBA(image.Rect(0, 0, outW, outH))
+	for oy := 0; oy < outH; oy++ {
+		sy0 := bounds.Min.Y + oy*h/outH
+		sy1 := max(sy0+1, bounds.Min.Y+(oy+1)*h/outH)
+		for ox := 0; ox < outW; ox++ {
+			sx0 := bounds.Min.X + ox*w/outW
+			sx1 := max(sx0+1, bounds.Min.X+(ox+1)*w/outW)
+
+			// Average in premultiplied space so transparent pixels do not darken edges
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			out.Set(ox, oy, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+const (
+	// legendScale enlarges the bitmap font used in PNG legends
+	legendScale = 2
+	// legendPadding is the margin around legend content in pixels
+	legendPadding = 8
+	// legendRowHeight is the height of one palette stop in pixels
+	legendRowHeight = 20
+	// legendSwatchWidth is the width of the color bar in pixels
+	legendSwatchWidth = 24
+)
+
+// LegendEntry is one labelled color in a legend
+type LegendEntry struct {
+	Label    string `json:"label"`
+	Category string `json:"category,omitempty"`
+	Color    string `json:"color"`
+}
+
+// Legend describes what the colors of a heatmap map type mean
+type Legend struct {
+	MapType        MapType       `json:"mapType"`
+	Title          string        `json:"title"`
+	Continuous     bool          `json:"continuous"`
+	HigherIsBetter bool          `json:"higherIsBetter"`
+	Entries        []LegendEntry `json:"entries"`
+}
+
+// hexColor formats a color as #rrggbb
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// stopLabel returns the legend label for a palette stop
+func stopLabel(s PaletteStop) string {
+	if s.Label != "" {
+		return s.Label
+	}
+	return strconv.FormatFloat(s.Value, 'f', -1, 64)
+}
+
+// NewLegend builds the legend for a map type
+func NewLegend(mapType MapType) (*Legend, error) {
+	palette, ok := Palettes[mapType]
+	if !ok {
+		return nil, fmt.Errorf("invalid map type: %s", mapType)
+	}
+	title := palette.Index
+	if palette.Units != "" {
+		title += " (" + units.Unit(palette.Units).Symbol() + ")"
+	}
+	legend := &Legend{
+		MapType:        mapType,
+		Title:          title,
+		Continuous:     palette.Continuous,
+		HigherIsBetter: palette.HigherIsBetter,
+	}
+	for _, s := range palette.Stops {
+		legend.Entries = append(legend.Entries, LegendEntry{
+			Label:    stopLabel(s),
+			Category: s.Category,
+			Color:    hexColor(s.Color),
+		})
+	}
+	return legend, nil
+}
+
+// entryText returns the text drawn next to a legend entry
+func (e LegendEntry) entryText() string {
+	if e.Category == "" {
+		return e.Label
+	}
+	return e.Label + "  " + e.Category
+}
+
+// RenderLegendPNG draws the legend for a map type as an image: a color bar with
+// one labelled row per palette stop, listed from best to worst air quality
+func RenderLegendPNG(mapType MapType) (*image.RGBA, error) {
+	legend, err := NewLegend(mapType)
+	if err != nil {
+		return nil, err
+	}
+	palette := Palettes[mapType]
+	stops := orderedStops(palette)
+
+	lineHeight := glyphHeight * legendScale
+	width := textWidth(legend.Title, legendScale)
+	for _, s := range stops {
+		entry := LegendEntry{Label: stopLabel(s), Category: s.Category}
+		width = max(width, legendSwatchWidth+legendPadding+textWidth(entry.entryText(), legendScale))
+	}
+	width += 2 * legendPadding
+	barTop := legendPadding + lineHeight + legendPadding
+	height := barTop + len(stops)*legendRowHeight + legendPadding
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{R: 255, G: 255, B: 255, A: 230}), image.Point{}, draw.Src)
+	drawText(img, legendPadding, legendPadding, legend.Title, legendScale, color.Black)
+
+	barHeight := len(stops) * legendRowHeight
+	for i, s := range stops {
+		rowTop := barTop + i*legendRowHeight
+		if !palette.Continuous {
+			swatch := image.Rect(legendPadding, rowTop+2, legendPadding+legendSwatchWidth, rowTop+legendRowHeight-2)
+			draw.Draw(img, swatch, image.NewUniform(s.Color), image.Point{}, draw.Src)
+		}
+		entry := LegendEntry{Label: stopLabel(s), Category: s.Category}
+		drawText(img, legendPadding+legendSwatchWidth+legendPadding, rowTop+(legendRowHeight-lineHeight)/2, entry.entryText(), legendScale, color.Black)
+	}
+	if palette.Continuous {
+		// Paint a gradient whose row centers match the stop labels
+		first, last := stops[0].Value, stops[len(stops)-1].Value
+		for y := 0; y < barHeight; y++ {
+			pos := (float64(y) - legendRowHeight/2) / float64(barHeight-legendRowHeight)
+			pos = max(0, min(1, pos))
+			c := palette.ColorAt(first + (last-first)*pos)
+			draw.Draw(img, image.Rect(legendPadding, barTop+y, legendPadding+legendSwatchWidth, barTop+y+1), image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+	return img, nil
+}
+
+// RenderLegendSVG draws the legend for a map type as an SVG document
+func RenderLegendSVG(mapType MapType) (string, error) {
+	legend, err := NewLegend(mapType)
+	if err != nil {
+		return "", err
+	}
+	palette := Palettes[mapType]
+	stops := orderedStops(palette)
+
+	const fontSize = 13
+	barTop := legendPadding + fontSize + legendPadding
+	height := barTop + len(stops)*legendRowHeight + legendPadding
+	width := 380
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="%d">`+"\n", width, height, fontSize)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white" fill-opacity="0.9"/>`+"\n")
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-weight="bold">%s</text>`+"\n", legendPadding, legendPadding+fontSize, html.EscapeString(legend.Title))
+
+	if palette.Continuous {
+		b.WriteString(`<defs><linearGradient id="bar" x1="0" y1="0" x2="0" y2="1">` + "\n")
+		for i, s := range stops {
+			offset := (float64(i)*legendRowHeight + legendRowHeight/2) / float64(len(stops)*legendRowHeight)
+			fmt.Fprintf(&b, `<stop offset="%.3f" stop-color="%s"/>`+"\n", offset, hexColor(s.Color))
+		}
+		b.WriteString("</linearGradient></defs>\n")
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="url(#bar)"/>`+"\n", legendPadding, barTop, legendSwatchWidth, len(stops)*legendRowHeight)
+	}
+	for i, s := range stops {
+		rowTop := barTop + i*legendRowHeight
+		if !palette.Continuous {
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", legendPadding, rowTop+2, legendSwatchWidth, legendRowHeight-4, hexColor(s.Color))
+		}
+		entry := LegendEntry{Label: stopLabel(s), Category: s.Category}
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", legendPadding+legendSwatchWidth+legendPadding, rowTop+legendRowHeight/2+fontSize/2-1, html.EscapeString(entry.entryText()))
+	}
+	b.WriteString("</svg>\n")
+	return b.String(), nil
+}
+
+// orderedStops returns palette stops from best to worst air quality
+func orderedStops(p HeatmapPalette) []PaletteStop {
+	stops := make([]PaletteStop, len(p.Stops))
+	copy(stops, p.Stops)
+	if p.HigherIsBetter {
+		for i, j := 0, len(stops)-1; i < j; i, j = i+1, j-1 {
+			stops[i], stops[j] = stops[j], stops[i]
+		}
+	}
+	return stops
+}
+
+// compositeLegend draws the legend for a map type onto the bottom-right corner
+// of img, clipped to the image if the legend is larger
+func compositeLegend(img *image.RGBA, mapType MapType) error {
+	legend, err := RenderLegendPNG(mapType)
+	if err != nil {
+		return err
+	}
+	b := img.Bounds()
+	lb := legend.Bounds()
+	origin := image.Pt(max(b.Min.X, b.Max.X-lb.Dx()), max(b.Min.Y, b.Max.Y-lb.Dy()))
+	draw.Draw(img, lb.Add(origin), legend, lb.Min, draw.Over)
+	return nil
+}
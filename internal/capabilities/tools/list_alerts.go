@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	ListAlertsToolName        = "list_air_quality_alerts"
+	ListAlertsToolDescription = "List the alerts created with create_air_quality_alert in this session with their state and expiry time."
+)
+
+var ListAlertsToolSchema = map[string]interface{}{
+	"type":       "object",
+	"properties": map[string]interface{}{},
+}
+
+// ListAlertsOutput defines the output for the list alerts tool
+type ListAlertsOutput struct {
+	Alerts []*Alert `json:"alerts"`
+}
+
+// NewListAlertsHandler creates a new list alerts handler
+func NewListAlertsHandler() func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		output := ListAlertsOutput{Alerts: Alerts.List(sessionID(request))}
+		if output.Alerts == nil {
+			output.Alerts = []*Alert{}
+		}
+
+		// Convert response to JSON string
+		jsonResp, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
+			}, nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: string(jsonResp)}},
+		}, nil
+	}
+}
@@ -0,0 +1,344 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	MosaicToolName        = "get_air_quality_heatmap_mosaic"
+	MosaicToolDescription = "Get a single air quality heatmap image covering a bounding box. Fetches and stitches all covering tiles, crops to the box and returns a PNG (or JPEG) image with its georeference (bounds and world file)."
+)
+
+const (
+	// maxMosaicTiles caps how many tiles a single mosaic may fetch
+	maxMosaicTiles = 64
+	// mosaicFetchConcurrency caps how many tiles are fetched at once
+	mosaicFetchConcurrency = 8
+	// defaultMosaicMaxSize is the default longest side of a mosaic in pixels
+	defaultMosaicMaxSize = 1024
+	// earthRadius is the Web Mercator sphere radius in meters
+	earthRadius = 6378137.0
+)
+
+var MosaicToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"mapType": map[string]interface{}{
+			"type":        "string",
+			"description": "Type of heatmap (UAQI_RED_GREEN UAQI_INDIGO_PERSIAN PM25_INDIGO_PERSIAN GBR_DEFRA DEU_UBA CAN_EC FRA_ATMO US_AQI)",
+		},
+		"north": map[string]interface{}{
+			"type":        "number",
+			"description": "Northern latitude of the bounding box",
+		},
+		"south": map[string]interface{}{
+			"type":        "number",
+			"description": "Southern latitude of the bounding box",
+		},
+		"east": map[string]interface{}{
+			"type":        "number",
+			"description": "Eastern longitude of the bounding box",
+		},
+		"west": map[string]interface{}{
+			"type":        "number",
+			"description": "Western longitude of the bounding box",
+		},
+		"zoom": map[string]interface{}{
+			"type":        "integer",
+			"description": "Zoom level (0-16). If omitted the highest zoom fitting maxSize is used",
+		},
+		"maxSize": map[string]interface{}{
+			"type":        "integer",
+			"description": "Longest side of the output image in pixels when zoom is omitted (default: 1024)",
+		},
+		"format": map[string]interface{}{
+			"type":        "string",
+			"description": "Image format (png jpeg, default: png). webp falls back to png",
+		},
+		"quality": map[string]interface{}{
+			"type":        "integer",
+			"description": "JPEG quality 1-100 (default: 80)",
+		},
+		"legend": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Draw the map type legend in the bottom-right corner (default: false)",
+		},
+	},
+	"required": []interface{}{"mapType", "north", "south", "east", "west"},
+}
+
+// MosaicInput defines the input for the heatmap mosaic tool
+type MosaicInput struct {
+	MapType string  `json:"mapType" jsonschema:"required,description=Type of heatmap"`
+	North   float64 `json:"north" jsonschema:"required,description=Northern latitude of the bounding box"`
+	South   float64 `json:"south" jsonschema:"required,description=Southern latitude of the bounding box"`
+	East    float64 `json:"east" jsonschema:"required,description=Eastern longitude of the bounding box"`
+	West    float64 `json:"west" jsonschema:"required,description=Western longitude of the bounding box"`
+	Zoom    *int    `json:"zoom,omitempty" jsonschema:"description=Zoom level (0-16)"`
+	MaxSize int     `json:"maxSize,omitempty" jsonschema:"description=Longest side of the output image in pixels when zoom is omitted (default: 1024)"`
+	Format  string  `json:"format,omitempty" jsonschema:"description=Image format (png jpeg, default: png)"`
+	Quality int     `json:"quality,omitempty" jsonschema:"description=JPEG quality 1-100 (default: 80)"`
+	Legend  bool    `json:"legend,omitempty" jsonschema:"description=Draw the map type legend in the bottom-right corner"`
+}
+
+// MosaicGeoreference describes where a mosaic image sits on the map
+type MosaicGeoreference struct {
+	MapType   string     `json:"mapType"`
+	Zoom      int        `json:"zoom"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	Tiles     int        `json:"tiles"`
+	Bounds    TileBounds `json:"bounds"`
+	CRS       string     `json:"crs"`
+	WorldFile string     `json:"worldFile"`
+}
+
+// mosaicPixelBox is a bounding box in world pixel coordinates at a zoom level
+type mosaicPixelBox struct {
+	zoom           int
+	x0, y0, x1, y1 int
+}
+
+// newMosaicPixelBox projects a bounding box to world pixels. A box whose west
+// edge is east of its east edge crosses the antimeridian.
+func newMosaicPixelBox(north, south, east, west float64, zoom int) mosaicPixelBox {
+	if east < west {
+		east += 360
+	}
+	px0, py0 := worldPixel(north, west, zoom)
+	px1, py1 := worldPixel(south, east, zoom)
+	return mosaicPixelBox{
+		zoom: zoom,
+		x0:   int(math.Floor(px0)),
+		y0:   int(math.Floor(py0)),
+		x1:   int(math.Ceil(px1)),
+		y1:   int(math.Ceil(py1)),
+	}
+}
+
+func (b mosaicPixelBox) width() int  { return b.x1 - b.x0 }
+func (b mosaicPixelBox) height() int { return b.y1 - b.y0 }
+
+// tileRange returns the first and last tile columns and rows covering the box
+func (b mosaicPixelBox) tileRange() (int, int, int, int) {
+	return b.x0 / TileSize, (b.x1 - 1) / TileSize, b.y0 / TileSize, (b.y1 - 1) / TileSize
+}
+
+// tileCount returns how many tiles cover the box
+func (b mosaicPixelBox) tileCount() int {
+	tx0, tx1, ty0, ty1 := b.tileRange()
+	return (tx1 - tx0 + 1) * (ty1 - ty0 + 1)
+}
+
+// bounds returns the geographic bounds of the pixel box
+func (b mosaicPixelBox) bounds() TileBounds {
+	north, west := worldPixelToLatLng(float64(b.x0), float64(b.y0), b.zoom)
+	south, east := worldPixelToLatLng(float64(b.x1), float64(b.y1), b.zoom)
+	if east > 180 {
+		east -= 360
+	}
+	return TileBounds{North: north, South: south, East: east, West: west}
+}
+
+// worldFile returns an ESRI world file for the box in EPSG:3857 meters
+func (b mosaicPixelBox) worldFile() string {
+	resolution := 2 * math.Pi * earthRadius / (float64(TileSize) * math.Exp2(float64(b.zoom)))
+	originX := (float64(b.x0)+0.5)*resolution - math.Pi*earthRadius
+	originY := math.Pi*earthRadius - (float64(b.y0)+0.5)*resolution
+	return fmt.Sprintf("%.10f\n0.0\n0.0\n%.10f\n%.10f\n%.10f\n", resolution, -resolution, originX, originY)
+}
+
+// NewMosaicHandler creates a new heatmap mosaic handler with the API key
+func NewMosaicHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input MosaicInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		// Validate bounding box; tiles only cover longitudes of ±180 and
+		// latitudes within the Web Mercator limits
+		if ValidateLatLng(input.North, 0) != nil || ValidateLatLng(input.South, 0) != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("north and south must be between %.4f and %.4f", -maxMercatorLatitude, maxMercatorLatitude)}},
+			}, nil
+		}
+		if ValidateLatLng(0, input.East) != nil || ValidateLatLng(0, input.West) != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "east and west must be between -180 and 180"}},
+			}, nil
+		}
+		if input.North <= input.South {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "north must be greater than south"}},
+			}, nil
+		}
+		if input.East == input.West {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "east and west must differ"}},
+			}, nil
+		}
+		if input.MaxSize == 0 {
+			input.MaxSize = defaultMosaicMaxSize
+		}
+
+		// Pick the zoom level
+		var box mosaicPixelBox
+		if input.Zoom != nil {
+			if *input.Zoom < 0 || *input.Zoom > MaxHeatmapZoom {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("zoom must be between 0 and %d", MaxHeatmapZoom)}},
+				}, nil
+			}
+			box = newMosaicPixelBox(input.North, input.South, input.East, input.West, *input.Zoom)
+		} else {
+			box = newMosaicPixelBox(input.North, input.South, input.East, input.West, 0)
+			for zoom := 1; zoom <= MaxHeatmapZoom; zoom++ {
+				next := newMosaicPixelBox(input.North, input.South, input.East, input.West, zoom)
+				if next.width() > input.MaxSize || next.height() > input.MaxSize || next.tileCount() > maxMosaicTiles {
+					break
+				}
+				box = next
+			}
+		}
+		if box.tileCount() > maxMosaicTiles {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("bounding box needs %d tiles at zoom %d, the limit is %d; use a lower zoom", box.tileCount(), box.zoom, maxMosaicTiles)}},
+			}, nil
+		}
+
+		// Fetch and stitch tiles
+		client := NewClient(apiKey)
+		img, err := buildMosaic(client, MapType(input.MapType), box)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to build heatmap mosaic: %v", err)}},
+			}, nil
+		}
+
+		if input.Legend {
+			if err := compositeLegend(img, MapType(input.MapType)); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to draw legend: %v", err)}},
+				}, nil
+			}
+		}
+
+		data, mimeType, _, err := encodeImage(img, ImageFormat(input.Format), input.Quality)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to encode mosaic: %v", err)}},
+			}, nil
+		}
+
+		georef := MosaicGeoreference{
+			MapType:   input.MapType,
+			Zoom:      box.zoom,
+			Width:     box.width(),
+			Height:    box.height(),
+			Tiles:     box.tileCount(),
+			Bounds:    box.bounds(),
+			CRS:       "EPSG:3857",
+			WorldFile: box.worldFile(),
+		}
+		jsonGeoref, err := json.MarshalIndent(georef, "", "  ")
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal georeference: %v", err)}},
+			}, nil
+		}
+
+		// Return success result with the image and its georeference
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.ImageContent{MIMEType: mimeType, Data: data},
+				&mcp.TextContent{Text: string(jsonGeoref)},
+			},
+		}, nil
+	}
+}
+
+// buildMosaic fetches every tile covering box concurrently and draws them onto a
+// single image cropped to the box
+func buildMosaic(client *Client, mapType MapType, box mosaicPixelBox) (*image.RGBA, error) {
+	tx0, tx1, ty0, ty1 := box.tileRange()
+	n := 1 << box.zoom
+	canvas := image.NewRGBA(image.Rect(0, 0, box.width(), box.height()))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, mosaicFetchConcurrency)
+	for ty := ty0; ty <= ty1; ty++ {
+		for tx := tx0; tx <= tx1; tx++ {
+			wg.Add(1)
+			go func(tx, ty int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				// Wrap columns that cross the antimeridian
+				data, err := client.GetHeatmapTile(mapType, box.zoom, tx%n, ty)
+				if err == nil {
+					var tile image.Image
+					tile, err = png.Decode(bytes.NewReader(data))
+					if err == nil {
+						offset := image.Pt(tx*TileSize-box.x0, ty*TileSize-box.y0)
+						mu.Lock()
+						draw.Draw(canvas, tile.Bounds().Add(offset), tile, tile.Bounds().Min, draw.Src)
+						mu.Unlock()
+					}
+				}
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("tile %d/%d/%d: %w", box.zoom, tx%n, ty, err)
+					}
+					mu.Unlock()
+				}
+			}(tx, ty)
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return canvas, nil
+}
@@ -0,0 +1,263 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/aqi"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	NowCastToolName        = "get_nowcast"
+	NowCastToolDescription = "Compute the US EPA NowCast for a location, as used by AirNow for PM2.5, PM10 and ozone. Weights the last hours of history and current conditions by how stable they are, and returns the NowCast concentration, weight factor and resulting US AQI per pollutant."
+)
+
+var NowCastToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": "Pollutants to compute (pm25 pm10 o3, default: all)",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+// nowcastPollutants are the pollutants AirNow reports NowCast values for
+var nowcastPollutants = []string{"pm25", "pm10", "o3"}
+
+// NowCastInput defines the input for the NowCast tool
+type NowCastInput struct {
+	Latitude     float64  `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude    float64  `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Pollutants   []string `json:"pollutants,omitempty" jsonschema:"description=Pollutants to compute (pm25 pm10 o3)"`
+	Units        string   `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	OutputFormat string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// PollutantNowCast is the NowCast of one pollutant
+type PollutantNowCast struct {
+	Code  string `json:"code"`
+	Units string `json:"units"`
+	aqi.NowCastResult
+	Aqi      int    `json:"aqi"`
+	Category string `json:"category"`
+}
+
+// NowCastOutput defines the output for the NowCast tool
+type NowCastOutput struct {
+	DateTime          string             `json:"dateTime"`
+	Aqi               int                `json:"aqi"`
+	Category          string             `json:"category,omitempty"`
+	DominantPollutant string             `json:"dominantPollutant,omitempty"`
+	Pollutants        []PollutantNowCast `json:"pollutants"`
+	Errors            []string           `json:"errors,omitempty"`
+}
+
+// CurrentConditionsWithNowCast is the current conditions response extended
+// with NowCast values and personal guidance
+type CurrentConditionsWithNowCast struct {
+	*CurrentConditionsResponse
+	NowCast          *NowCastOutput    `json:"nowcast,omitempty"`
+	PersonalGuidance *PersonalGuidance `json:"personalGuidance,omitempty"`
+	// PersonalGuidanceNote explains why a stored or passed profile could not
+	// be applied
+	PersonalGuidanceNote string `json:"personalGuidanceNote,omitempty"`
+}
+
+// NewNowCastHandler creates a new NowCast handler with the API key
+func NewNowCastHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input NowCastInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		for _, code := range input.Pollutants {
+			if aqi.NowCastHours(code) == 0 {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("NowCast is not defined for %q, expected one of %v", code, nowcastPollutants)}},
+				}, nil
+			}
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		output, err := computeNowCast(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, nil, input.Pollutants)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compute NowCast: %v", err)}},
+			}, nil
+		}
+
+		convertNowCast(output, target)
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, timezone.Lookup(input.Latitude, input.Longitude)), nil
+	}
+}
+
+// computeNowCast computes NowCast values from recent history and current
+// conditions. current is fetched when nil or when it lacks concentrations.
+func computeNowCast(client *Client, location LatLng, current *CurrentConditionsResponse, pollutants []string) (*NowCastOutput, error) {
+	if len(pollutants) == 0 {
+		pollutants = nowcastPollutants
+	}
+	if current == nil || !hasConcentrations(current.Pollutants) {
+		universalAqi := true
+		resp, err := client.GetCurrentConditions(CurrentConditionsRequest{
+			Location:          location,
+			ExtraComputations: []ExtraComputation{ExtraComputationPollutantConcentration},
+			UniversalAqi:      &universalAqi,
+		})
+		if err != nil {
+			return nil, err
+		}
+		current = resp
+	}
+
+	// The current hour plus enough preceding hours for the longest NowCast
+	hours := 0
+	for _, code := range pollutants {
+		hours = max(hours, aqi.NowCastHours(code))
+	}
+	hourStart := time.Now().UTC().Truncate(time.Hour)
+	if t, err := time.Parse(time.RFC3339, current.DateTime); err == nil {
+		hourStart = t.UTC().Truncate(time.Hour)
+	}
+	history, err := fetchHistoryPeriod(client, location, hourStart.Add(-time.Duration(hours-1)*time.Hour), hourStart, []ExtraComputation{
+		ExtraComputationPollutantConcentration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshots := append(historySnapshots(history), hourSnapshot{
+		DateTime:   hourStart.Format(time.RFC3339),
+		Pollutants: current.Pollutants,
+	})
+	series, seriesUnits := pollutantSeries(snapshots)
+
+	output := &NowCastOutput{DateTime: hourStart.Format(time.RFC3339), Pollutants: []PollutantNowCast{}}
+	for _, code := range pollutants {
+		// Order hourly values from the current hour backwards
+		hourly := make([]float64, aqi.NowCastHours(code))
+		for i := range hourly {
+			hourly[i] = math.NaN()
+		}
+		for _, p := range series[code] {
+			if age := int(hourStart.Sub(p.At) / time.Hour); age >= 0 && age < len(hourly) {
+				hourly[age] = p.Value
+			}
+		}
+
+		result, err := aqi.NowCast(code, hourly)
+		if err != nil {
+			output.Errors = append(output.Errors, err.Error())
+			continue
+		}
+		index, err := aqi.Compute(aqi.StandardUSEPA, map[string]aqi.Concentration{
+			code: {Value: result.Concentration, Units: units.Unit(seriesUnits[code])},
+		})
+		if err != nil {
+			output.Errors = append(output.Errors, err.Error())
+			continue
+		}
+		output.Pollutants = append(output.Pollutants, PollutantNowCast{
+			Code:          code,
+			Units:         seriesUnits[code],
+			NowCastResult: *result,
+			Aqi:           index.Value,
+			Category:      index.Category,
+		})
+		if index.Value > output.Aqi || output.DominantPollutant == "" {
+			output.Aqi, output.Category, output.DominantPollutant = index.Value, index.Category, code
+		}
+	}
+	if len(output.Pollutants) == 0 {
+		return nil, fmt.Errorf("no NowCast could be computed: %v", output.Errors)
+	}
+	return output, nil
+}
+
+// convertNowCast converts NowCast concentrations in place to the target units
+func convertNowCast(output *NowCastOutput, target units.Unit) {
+	if target == "" {
+		return
+	}
+	for i, p := range output.Pollutants {
+		value, err := units.Convert(p.Code, p.Concentration, units.Unit(p.Units), target)
+		if err != nil {
+			continue
+		}
+		output.Pollutants[i].Concentration = roundTo(value, 3)
+		output.Pollutants[i].Units = string(target)
+	}
+}
+
+// hasConcentrations reports whether any pollutant carries a concentration
+func hasConcentrations(pollutants []Pollutant) bool {
+	for _, p := range pollutants {
+		if p.Concentration != nil {
+			return true
+		}
+	}
+	return false
+}
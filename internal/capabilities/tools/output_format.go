@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// outputFormatDescription documents the outputFormat input shared by data tools
+const outputFormatDescription = "Response format (json compact-json markdown summary, default: json). compact-json drops colors and display names, markdown renders fields and tables, summary returns one paragraph; both of those also return the full response as structured content"
+
+// renderResult renders a tool's output in the requested format. Timestamps
+// get local copies in zone when it is set. Formats other than json keep the
+// full output available as structured content.
+func renderResult(output interface{}, format render.Format, zone *time.Location) *mcp.CallToolResult {
+	if zone != nil {
+		localized, err := render.Localize(output, zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to localize response: %v", err)}},
+			}
+		}
+		output = localized
+	}
+
+	text, err := render.Render(format, output)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to render response: %v", err)}},
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+	if format == render.FormatMarkdown || format == render.FormatSummary {
+		result.StructuredContent = output
+	}
+	return result
+}
+
+// withResolvedTime adds the resolved time arguments to the top of an output
+// as resolvedTime, leaving the output unchanged when there are none
+func withResolvedTime(output interface{}, resolved *ResolvedTime) (interface{}, error) {
+	if resolved == nil {
+		return output, nil
+	}
+	return render.Prepend(output, "resolvedTime", resolved)
+}
@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// MapTypes lists every heatmap map type served by the API
+var MapTypes = []MapType{
+	MapTypeUAQIRedGreen,
+	MapTypeUAQIIndigoPersian,
+	MapTypePM25IndigoPersian,
+	MapTypeGBRDefra,
+	MapTypeDEUUba,
+	MapTypeCANEc,
+	MapTypeFRAAtmo,
+	MapTypeUSAQI,
+}
+
+// ParseMapType validates a map type name
+func ParseMapType(s string) (MapType, error) {
+	for _, t := range MapTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid map type: %s", s)
+}
+
+// PaletteStop is a color used by a heatmap at a given index value
+type PaletteStop struct {
+	Value float64 `json:"value"`
+	// Label overrides the value in legends, e.g. for categories spanning a range
+	Label    string     `json:"label,omitempty"`
+	Color    color.RGBA `json:"-"`
+	Category string     `json:"category,omitempty"`
+}
+
+// HeatmapPalette describes how a heatmap map type colors index values. The
+// stops approximate the published palettes; heatmap tiles are also smoothed, so
+// values read back from colors are estimates.
+type HeatmapPalette struct {
+	MapType MapType `json:"mapType"`
+	Index   string  `json:"index"`
+	Units   string  `json:"units,omitempty"`
+	// Continuous palettes blend between stops; otherwise each stop is a category
+	Continuous     bool          `json:"continuous"`
+	HigherIsBetter bool          `json:"higherIsBetter"`
+	Stops          []PaletteStop `json:"stops"`
+}
+
+func rgb(r, g, b uint8) color.RGBA { return color.RGBA{R: r, G: g, B: b, A: 255} }
+
+// Palettes holds the palette for every map type
+var Palettes = map[MapType]HeatmapPalette{
+	MapTypeUAQIRedGreen: {
+		MapType: MapTypeUAQIRedGreen, Index: "Universal AQI", Continuous: true, HigherIsBetter: true,
+		Stops: []PaletteStop{
+			{Value: 0, Color: rgb(0x9e, 0x00, 0x00), Category: "Poor air quality"},
+			{Value: 20, Color: rgb(0xff, 0x00, 0x00), Category: "Low air quality"},
+			{Value: 40, Color: rgb(0xff, 0x8c, 0x00), Category: "Moderate air quality"},
+			{Value: 60, Color: rgb(0xff, 0xff, 0x00), Category: "Good air quality"},
+			{Value: 80, Color: rgb(0x84, 0xcf, 0x33), Category: "Excellent air quality"},
+			{Value: 100, Color: rgb(0x00, 0x9e, 0x3a), Category: "Excellent air quality"},
+		},
+	},
+	MapTypeUAQIIndigoPersian: {
+		MapType: MapTypeUAQIIndigoPersian, Index: "Universal AQI", Continuous: true, HigherIsBetter: true,
+		Stops: []PaletteStop{
+			{Value: 0, Color: rgb(0x80, 0x00, 0x20), Category: "Poor air quality"},
+			{Value: 20, Color: rgb(0xd0, 0x21, 0x4a), Category: "Low air quality"},
+			{Value: 40, Color: rgb(0xe8, 0x6a, 0x8a), Category: "Moderate air quality"},
+			{Value: 60, Color: rgb(0x9b, 0x8c, 0xe0), Category: "Good air quality"},
+			{Value: 80, Color: rgb(0x5c, 0x4d, 0xc8), Category: "Excellent air quality"},
+			{Value: 100, Color: rgb(0x32, 0x1a, 0x8c), Category: "Excellent air quality"},
+		},
+	},
+	MapTypePM25IndigoPersian: {
+		MapType: MapTypePM25IndigoPersian, Index: "PM2.5 concentration", Units: "MICROGRAMS_PER_CUBIC_METER", Continuous: true,
+		Stops: []PaletteStop{
+			{Value: 0, Color: rgb(0x32, 0x1a, 0x8c)},
+			{Value: 12, Color: rgb(0x5c, 0x4d, 0xc8)},
+			{Value: 35, Color: rgb(0x9b, 0x8c, 0xe0)},
+			{Value: 55, Color: rgb(0xe8, 0x6a, 0x8a)},
+			{Value: 150, Color: rgb(0xd0, 0x21, 0x4a)},
+			{Value: 250, Color: rgb(0x80, 0x00, 0x20)},
+		},
+	},
+	MapTypeGBRDefra: {
+		MapType: MapTypeGBRDefra, Index: "Daily Air Quality Index (UK)",
+		Stops: []PaletteStop{
+			{Value: 1, Color: rgb(0x9c, 0xff, 0x9c), Category: "Low"},
+			{Value: 2, Color: rgb(0x31, 0xff, 0x00), Category: "Low"},
+			{Value: 3, Color: rgb(0x31, 0xcf, 0x00), Category: "Low"},
+			{Value: 4, Color: rgb(0xff, 0xff, 0x00), Category: "Moderate"},
+			{Value: 5, Color: rgb(0xff, 0xcf, 0x00), Category: "Moderate"},
+			{Value: 6, Color: rgb(0xff, 0x9a, 0x00), Category: "Moderate"},
+			{Value: 7, Color: rgb(0xff, 0x64, 0x64), Category: "High"},
+			{Value: 8, Color: rgb(0xff, 0x00, 0x00), Category: "High"},
+			{Value: 9, Color: rgb(0x99, 0x00, 0x00), Category: "High"},
+			{Value: 10, Color: rgb(0xce, 0x30, 0xff), Category: "Very High"},
+		},
+	},
+	MapTypeDEUUba: {
+		MapType: MapTypeDEUUba, Index: "Luftqualitätsindex (Germany)",
+		Stops: []PaletteStop{
+			{Value: 1, Color: rgb(0x50, 0xf0, 0xe6), Category: "Very good"},
+			{Value: 2, Color: rgb(0x50, 0xcc, 0xaa), Category: "Good"},
+			{Value: 3, Color: rgb(0xf0, 0xe6, 0x41), Category: "Moderate"},
+			{Value: 4, Color: rgb(0xff, 0x50, 0x50), Category: "Poor"},
+			{Value: 5, Color: rgb(0x96, 0x00, 0x32), Category: "Very poor"},
+		},
+	},
+	MapTypeCANEc: {
+		MapType: MapTypeCANEc, Index: "Air Quality Health Index (Canada)",
+		Stops: []PaletteStop{
+			{Value: 1, Color: rgb(0x00, 0xcc, 0xff), Category: "Low risk"},
+			{Value: 2, Color: rgb(0x00, 0x99, 0xcc), Category: "Low risk"},
+			{Value: 3, Color: rgb(0x00, 0x66, 0x99), Category: "Low risk"},
+			{Value: 4, Color: rgb(0xff, 0xff, 0x00), Category: "Moderate risk"},
+			{Value: 5, Color: rgb(0xff, 0xcc, 0x00), Category: "Moderate risk"},
+			{Value: 6, Color: rgb(0xff, 0x99, 0x33), Category: "Moderate risk"},
+			{Value: 7, Color: rgb(0xff, 0x66, 0x66), Category: "High risk"},
+			{Value: 8, Color: rgb(0xff, 0x00, 0x00), Category: "High risk"},
+			{Value: 9, Color: rgb(0xcc, 0x00, 0x00), Category: "High risk"},
+			{Value: 10, Color: rgb(0x99, 0x00, 0x00), Category: "High risk"},
+			{Value: 11, Color: rgb(0x66, 0x00, 0x00), Category: "Very high risk"},
+		},
+	},
+	MapTypeFRAAtmo: {
+		MapType: MapTypeFRAAtmo, Index: "Indice ATMO (France)",
+		Stops: []PaletteStop{
+			{Value: 1, Color: rgb(0x50, 0xf0, 0xe6), Category: "Good"},
+			{Value: 2, Color: rgb(0x50, 0xcc, 0xaa), Category: "Medium"},
+			{Value: 3, Color: rgb(0xf0, 0xe6, 0x41), Category: "Degraded"},
+			{Value: 4, Color: rgb(0xff, 0x50, 0x50), Category: "Bad"},
+			{Value: 5, Color: rgb(0x96, 0x00, 0x32), Category: "Very bad"},
+			{Value: 6, Color: rgb(0x87, 0x21, 0x81), Category: "Extremely bad"},
+		},
+	},
+	MapTypeUSAQI: {
+		MapType: MapTypeUSAQI, Index: "AQI (US)",
+		Stops: []PaletteStop{
+			{Value: 25, Label: "0-50", Color: rgb(0x00, 0xe4, 0x00), Category: "Good"},
+			{Value: 75, Label: "51-100", Color: rgb(0xff, 0xff, 0x00), Category: "Moderate"},
+			{Value: 125, Label: "101-150", Color: rgb(0xff, 0x7e, 0x00), Category: "Unhealthy for Sensitive Groups"},
+			{Value: 175, Label: "151-200", Color: rgb(0xff, 0x00, 0x00), Category: "Unhealthy"},
+			{Value: 250, Label: "201-300", Color: rgb(0x8f, 0x3f, 0x97), Category: "Very Unhealthy"},
+			{Value: 400, Label: "301-500", Color: rgb(0x7e, 0x00, 0x23), Category: "Hazardous"},
+		},
+	},
+}
+
+// colorDistance returns the Euclidean distance between two colors in RGB space
+func colorDistance(a, b color.RGBA) float64 {
+	dr := float64(a.R) - float64(b.R)
+	dg := float64(a.G) - float64(b.G)
+	db := float64(a.B) - float64(b.B)
+	return math.Sqrt(dr*dr + dg*dg + db*db)
+}
+
+// lerpColor blends two colors, t=0 returning a and t=1 returning b
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t)) }
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
+// ColorAt returns the palette color for an index value
+func (p HeatmapPalette) ColorAt(value float64) color.RGBA {
+	stops := p.Stops
+	if value <= stops[0].Value {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		if value > stops[i].Value {
+			continue
+		}
+		if !p.Continuous {
+			// Categorical palettes switch color halfway between stops
+			if value-stops[i-1].Value < stops[i].Value-value {
+				return stops[i-1].Color
+			}
+			return stops[i].Color
+		}
+		t := (value - stops[i-1].Value) / (stops[i].Value - stops[i-1].Value)
+		return lerpColor(stops[i-1].Color, stops[i].Color, t)
+	}
+	return stops[len(stops)-1].Color
+}
+
+// Lookup reverse-maps a color to the closest index value in the palette and
+// returns the value, its category and the RGB distance to the matched color
+func (p HeatmapPalette) Lookup(c color.RGBA) (float64, string, float64) {
+	bestValue, bestDistance := p.Stops[0].Value, math.Inf(1)
+	if !p.Continuous {
+		category := ""
+		for _, s := range p.Stops {
+			if d := colorDistance(c, s.Color); d < bestDistance {
+				bestValue, bestDistance, category = s.Value, d, s.Category
+			}
+		}
+		return bestValue, category, bestDistance
+	}
+
+	// Continuous palettes are sampled along each segment between stops
+	const steps = 32
+	for i := 1; i < len(p.Stops); i++ {
+		a, b := p.Stops[i-1], p.Stops[i]
+		for step := 0; step <= steps; step++ {
+			t := float64(step) / steps
+			if d := colorDistance(c, lerpColor(a.Color, b.Color, t)); d < bestDistance {
+				bestValue, bestDistance = a.Value+(b.Value-a.Value)*t, d
+			}
+		}
+	}
+	return bestValue, p.CategoryFor(bestValue), bestDistance
+}
+
+// CategoryFor returns the category of the stop at or below value
+func (p HeatmapPalette) CategoryFor(value float64) string {
+	category := p.Stops[0].Category
+	for _, s := range p.Stops {
+		if value >= s.Value {
+			category = s.Category
+		}
+	}
+	return category
+}
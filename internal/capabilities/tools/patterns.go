@@ -0,0 +1,497 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	PatternsToolName        = "analyze_air_quality_patterns"
+	PatternsToolDescription = "Build hour-of-day and day-of-week average profiles from up to 30 days of history for a location, in local time. Identifies the typical peak hours and day of each pollutant and the Universal AQI, such as rush-hour NO2, and returns the profiles as compact tables with an optional chart."
+)
+
+var PatternsToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"days": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of past days to profile (default: 14 max: 30)",
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": "Pollutant codes to profile (default: all reported)",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"chart": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Also return a PNG chart of the hour-of-day profiles with peak hours highlighted (default: false)",
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+const (
+	// defaultPatternDays is how many days are profiled when none are requested
+	defaultPatternDays = 14
+	// peakRatio is how far above the mean of its profile an hour must be to
+	// count as a peak
+	peakRatio = 1.1
+	// maxPeakHours is the number of peak hours reported per series
+	maxPeakHours = 3
+
+	// Chart layout in pixels
+	chartScale      = 2
+	chartPadding    = 10
+	chartBarWidth   = 12
+	chartPlotHeight = 80
+)
+
+var (
+	chartBarColor  = color.RGBA{R: 120, G: 144, B: 176, A: 255}
+	chartPeakColor = color.RGBA{R: 230, G: 120, B: 40, A: 255}
+	chartAxisColor = color.RGBA{R: 90, G: 90, B: 90, A: 255}
+)
+
+// PatternsInput defines the input for the patterns tool
+type PatternsInput struct {
+	Latitude     float64  `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude    float64  `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Days         int      `json:"days,omitempty" jsonschema:"description=Number of past days to profile (default: 14 max: 30)"`
+	Pollutants   []string `json:"pollutants,omitempty" jsonschema:"description=Pollutant codes to profile"`
+	Units        string   `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	Chart        bool     `json:"chart,omitempty" jsonschema:"description=Also return a PNG chart of the hour-of-day profiles"`
+	OutputFormat string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// ProfileValue is the average of one series in a profile row
+type ProfileValue struct {
+	Code  string  `json:"code"`
+	Value float64 `json:"value"`
+}
+
+// ProfileRow is one hour of the day or day of the week with the average of
+// every series
+type ProfileRow struct {
+	Hour   string         `json:"hour,omitempty"`
+	Day    string         `json:"day,omitempty"`
+	Hours  int            `json:"hours"`
+	Values []ProfileValue `json:"values"`
+}
+
+// SeriesPattern describes the typical cycle of one series
+type SeriesPattern struct {
+	Code  string  `json:"code"`
+	Units string  `json:"units,omitempty"`
+	Mean  float64 `json:"mean"`
+	// PeakHours are the local hours with the worst air, worst first
+	PeakHours []string `json:"peakHours"`
+	// PeakToMean is the worst hourly average relative to the mean
+	PeakToMean float64 `json:"peakToMean"`
+	CleanHour  string  `json:"cleanHour"`
+	PeakDay    string  `json:"peakDay,omitempty"`
+	CleanDay   string  `json:"cleanDay,omitempty"`
+	Pattern    string  `json:"pattern"`
+}
+
+// PatternsOutput defines the output for the patterns tool
+type PatternsOutput struct {
+	PeriodStart string          `json:"periodStart"`
+	PeriodEnd   string          `json:"periodEnd"`
+	Series      []SeriesPattern `json:"series"`
+	HourOfDay   []ProfileRow    `json:"hourOfDay"`
+	DayOfWeek   []ProfileRow    `json:"dayOfWeek"`
+}
+
+// patternSeries is one series to profile
+type patternSeries struct {
+	code   string
+	units  string
+	points []seriesPoint
+	// lowerIsWorse is set for the Universal AQI, which falls as air gets worse
+	lowerIsWorse bool
+}
+
+// NewPatternsHandler creates a new patterns handler with the API key
+func NewPatternsHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input PatternsInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		if input.Days == 0 {
+			input.Days = defaultPatternDays
+		}
+		if input.Days < 1 || input.Days > maxAverageDays {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("days must be between 1 and %d", maxAverageDays)}},
+			}, nil
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		end := time.Now().UTC().Truncate(time.Hour)
+		start := end.Add(-time.Duration(input.Days) * 24 * time.Hour)
+		client := NewClient(apiKey)
+		hours, err := fetchHistoryPeriod(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, start, end, []ExtraComputation{
+			ExtraComputationPollutantConcentration,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get history: %v", err)}},
+			}, nil
+		}
+
+		// Convert concentrations to the requested units
+		for i := range hours {
+			convertPollutants(hours[i].Pollutants, target)
+		}
+
+		var series []patternSeries
+		snapshots := historySnapshots(hours)
+		if points := indexSeries(snapshots)[universalAqiCode]; len(points) > 0 && (len(input.Pollutants) == 0 || slices.Contains(input.Pollutants, universalAqiCode)) {
+			series = append(series, patternSeries{code: universalAqiCode, points: points, lowerIsWorse: true})
+		}
+		pollutants, seriesUnits := pollutantSeries(snapshots)
+		for _, code := range sortedKeys(pollutants) {
+			if len(input.Pollutants) > 0 && !slices.Contains(input.Pollutants, code) {
+				continue
+			}
+			series = append(series, patternSeries{code: code, units: seriesUnits[code], points: pollutants[code]})
+		}
+
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		output := analyzePatterns(series, zone)
+		output.PeriodStart, output.PeriodEnd = start.Format(time.RFC3339), end.Format(time.RFC3339)
+
+		// Render response in the requested format
+		result := renderResult(output, outputFormat, zone)
+		if !input.Chart || result.IsError || len(output.Series) == 0 {
+			return result, nil
+		}
+		data, mimeType, _, err := encodeImage(renderPatternChart(output), ImageFormatPNG, 0)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to encode chart: %v", err)}},
+			}, nil
+		}
+		result.Content = append(result.Content, &mcp.ImageContent{MIMEType: mimeType, Data: data})
+		return result, nil
+	}
+}
+
+// analyzePatterns averages each series by local hour of day and day of week
+// and finds its typical peaks
+func analyzePatterns(series []patternSeries, zone *time.Location) *PatternsOutput {
+	output := &PatternsOutput{
+		Series:    []SeriesPattern{},
+		HourOfDay: make([]ProfileRow, 24),
+		DayOfWeek: make([]ProfileRow, 7),
+	}
+	// Weeks start on Monday
+	days := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+	for h := range output.HourOfDay {
+		output.HourOfDay[h] = ProfileRow{Hour: hourLabel(h), Values: []ProfileValue{}}
+	}
+	for i, d := range days {
+		output.DayOfWeek[i] = ProfileRow{Day: d.String(), Values: []ProfileValue{}}
+	}
+
+	hourCounts := make([]map[time.Time]bool, 24)
+	dayCounts := make([]map[time.Time]bool, 7)
+	for i := range hourCounts {
+		hourCounts[i] = map[time.Time]bool{}
+	}
+	for i := range dayCounts {
+		dayCounts[i] = map[time.Time]bool{}
+	}
+
+	for _, s := range series {
+		var byHour [24][]float64
+		var byDay [7][]float64
+		for _, p := range s.points {
+			local := p.At.In(zone)
+			day := (int(local.Weekday()) + 6) % 7
+			byHour[local.Hour()] = append(byHour[local.Hour()], p.Value)
+			byDay[day] = append(byDay[day], p.Value)
+			hourCounts[local.Hour()][p.At] = true
+			dayCounts[day][p.At] = true
+		}
+
+		hourly := make([]*float64, 24)
+		for h, values := range byHour {
+			if len(values) == 0 {
+				continue
+			}
+			v := roundTo(mean(values), 2)
+			hourly[h] = &v
+			output.HourOfDay[h].Values = append(output.HourOfDay[h].Values, ProfileValue{Code: s.code, Value: v})
+		}
+		daily := make([]*float64, 7)
+		for d, values := range byDay {
+			if len(values) == 0 {
+				continue
+			}
+			v := roundTo(mean(values), 2)
+			daily[d] = &v
+			output.DayOfWeek[d].Values = append(output.DayOfWeek[d].Values, ProfileValue{Code: s.code, Value: v})
+		}
+
+		pattern := SeriesPattern{
+			Code:      s.code,
+			Units:     s.units,
+			Mean:      roundTo(mean(seriesValues(s.points)), 2),
+			PeakHours: []string{},
+		}
+		peaks := peakHours(hourly, s.lowerIsWorse)
+		for _, h := range peaks {
+			pattern.PeakHours = append(pattern.PeakHours, hourLabel(h))
+		}
+		if worst := extremeIndex(hourly, s.lowerIsWorse); worst >= 0 && pattern.Mean != 0 {
+			pattern.PeakToMean = roundTo(*hourly[worst]/pattern.Mean, 2)
+		}
+		if clean := extremeIndex(hourly, !s.lowerIsWorse); clean >= 0 {
+			pattern.CleanHour = hourLabel(clean)
+		}
+		if worst := extremeIndex(daily, s.lowerIsWorse); worst >= 0 {
+			pattern.PeakDay = days[worst].String()
+		}
+		if clean := extremeIndex(daily, !s.lowerIsWorse); clean >= 0 {
+			pattern.CleanDay = days[clean].String()
+		}
+		pattern.Pattern = describePeaks(peaks)
+		output.Series = append(output.Series, pattern)
+	}
+
+	for h := range output.HourOfDay {
+		output.HourOfDay[h].Hours = len(hourCounts[h])
+	}
+	for d := range output.DayOfWeek {
+		output.DayOfWeek[d].Hours = len(dayCounts[d])
+	}
+	return output
+}
+
+// peakHours returns up to maxPeakHours local maxima of a 24-hour profile,
+// worst first, that stand out from its mean by peakRatio. Profiles where
+// lower is worse are mirrored so their minima count as peaks.
+func peakHours(hourly []*float64, lowerIsWorse bool) []int {
+	var values []float64
+	for _, v := range hourly {
+		if v != nil {
+			values = append(values, *v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	avg := mean(values)
+	worse := func(a, b float64) bool {
+		if lowerIsWorse {
+			return a < b
+		}
+		return a > b
+	}
+	stands := func(v float64) bool {
+		if lowerIsWorse {
+			return v*peakRatio <= avg
+		}
+		return v >= avg*peakRatio
+	}
+
+	var peaks []int
+	for h, v := range hourly {
+		if v == nil || !stands(*v) {
+			continue
+		}
+		// The day wraps, so 23:00 and 00:00 are neighbours. A plateau is
+		// reported once, at its last hour.
+		prev, next := hourly[(h+23)%24], hourly[(h+1)%24]
+		if (prev != nil && worse(*prev, *v)) || (next != nil && !worse(*v, *next)) {
+			continue
+		}
+		peaks = append(peaks, h)
+	}
+	sort.SliceStable(peaks, func(i, j int) bool { return worse(*hourly[peaks[i]], *hourly[peaks[j]]) })
+	if len(peaks) > maxPeakHours {
+		peaks = peaks[:maxPeakHours]
+	}
+	return peaks
+}
+
+// extremeIndex returns the position of the highest value, or of the lowest
+// when lowest is set, or -1 when there are none
+func extremeIndex(values []*float64, lowest bool) int {
+	best := -1
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if best < 0 || (lowest && *v < *values[best]) || (!lowest && *v > *values[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// describePeaks names the parts of the day the peak hours fall in
+func describePeaks(peaks []int) string {
+	if len(peaks) == 0 {
+		return "no clear daily peak"
+	}
+	var parts []string
+	for _, h := range slices.Sorted(slices.Values(peaks)) {
+		var part string
+		switch {
+		case h >= 6 && h <= 9:
+			part = "morning rush hour"
+		case h >= 10 && h <= 15:
+			part = "midday"
+		case h >= 16 && h <= 19:
+			part = "evening rush hour"
+		default:
+			part = "night"
+		}
+		if !slices.Contains(parts, part) {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+// hourLabel formats an hour of the day as HH:00
+func hourLabel(h int) string {
+	return fmt.Sprintf("%02d:00", h)
+}
+
+// renderPatternChart draws one bar chart of the hour-of-day profile per
+// series, stacked vertically, with peak hours highlighted
+func renderPatternChart(output *PatternsOutput) *image.RGBA {
+	lineHeight := glyphHeight * chartScale
+	plotWidth := 24 * chartBarWidth
+	labelWidth := textWidth("00000.0", chartScale)
+	panelHeight := lineHeight + chartPadding + chartPlotHeight + chartPadding + lineHeight + 2*chartPadding
+	width := chartPadding + labelWidth + chartPadding + plotWidth + chartPadding
+	height := len(output.Series)*panelHeight + chartPadding
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	for i, s := range output.Series {
+		top := chartPadding + i*panelHeight
+		title := s.Code
+		if s.Units != "" {
+			title += " (" + units.Unit(s.Units).Symbol() + ")"
+		}
+		if len(s.PeakHours) > 0 {
+			title += " PEAK " + strings.Join(s.PeakHours, ", ")
+		}
+		drawText(img, chartPadding, top, title, chartScale, color.Black)
+
+		// Scale bars to the highest hourly average of the series
+		values := make([]float64, 24)
+		present := make([]bool, 24)
+		highest := 0.0
+		for h, row := range output.HourOfDay {
+			for _, v := range row.Values {
+				if v.Code == s.Code {
+					values[h], present[h] = v.Value, true
+					highest = max(highest, v.Value)
+				}
+			}
+		}
+		plotLeft := chartPadding + labelWidth + chartPadding
+		plotTop := top + lineHeight + chartPadding
+		plotBottom := plotTop + chartPlotHeight
+		drawText(img, chartPadding, plotTop, formatAxisValue(highest), chartScale, chartAxisColor)
+		drawText(img, chartPadding+labelWidth-textWidth("0", chartScale), plotBottom-lineHeight, "0", chartScale, chartAxisColor)
+		for h := 0; h < 24; h++ {
+			if !present[h] || highest <= 0 {
+				continue
+			}
+			barColor := chartBarColor
+			if slices.Contains(s.PeakHours, hourLabel(h)) {
+				barColor = chartPeakColor
+			}
+			barHeight := int(values[h] / highest * chartPlotHeight)
+			x := plotLeft + h*chartBarWidth
+			draw.Draw(img, image.Rect(x+1, plotBottom-barHeight, x+chartBarWidth-1, plotBottom), image.NewUniform(barColor), image.Point{}, draw.Src)
+		}
+		draw.Draw(img, image.Rect(plotLeft, plotBottom, plotLeft+plotWidth, plotBottom+1), image.NewUniform(chartAxisColor), image.Point{}, draw.Src)
+		for h := 0; h < 24; h += 6 {
+			drawText(img, plotLeft+h*chartBarWidth, plotBottom+chartPadding/2, fmt.Sprintf("%02d", h), chartScale, chartAxisColor)
+		}
+	}
+	return img
+}
+
+// formatAxisValue formats an axis label with at most one decimal
+func formatAxisValue(v float64) string {
+	if v >= 100 {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0")
+}
@@ -0,0 +1,383 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	PeriodChangeToolName        = "compare_air_quality_periods"
+	PeriodChangeToolDescription = "Compare current air quality at a location with the same hour yesterday, the same hour last week and the average of the past days (default: 30). Returns per-pollutant and per-index absolute change, percent change and ratio, category changes, and highlights such as \"PM2.5 is 3x the 30-day average\"."
+)
+
+var PeriodChangeToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"averageDays": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of past days averaged for the usual level (default: 30 max: 30)",
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": "Pollutant codes to compare (default: all reported)",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+const (
+	// BaselineYesterday is the same hour one day earlier
+	BaselineYesterday = "yesterday"
+	// BaselineLastWeek is the same hour seven days earlier
+	BaselineLastWeek = "lastWeek"
+	// BaselineAverage is the mean over the averaged days
+	BaselineAverage = "average"
+
+	// maxAverageDays is how many days of history the average can cover
+	maxAverageDays = maxHistoryHours / 24
+	// notableRatio is how many times above or below its baseline a value must
+	// be to be highlighted
+	notableRatio = 1.5
+)
+
+// PeriodChangeInput defines the input for the period change tool
+type PeriodChangeInput struct {
+	Latitude     float64  `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude    float64  `json:"longitude" jsonschema:"required,description=Location longitude"`
+	AverageDays  int      `json:"averageDays,omitempty" jsonschema:"description=Number of past days averaged (default: 30 max: 30)"`
+	Pollutants   []string `json:"pollutants,omitempty" jsonschema:"description=Pollutant codes to compare"`
+	Units        string   `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	OutputFormat string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// ValueChange is the change of one index or pollutant from a baseline
+type ValueChange struct {
+	Code     string  `json:"code"`
+	Units    string  `json:"units,omitempty"`
+	Current  float64 `json:"current"`
+	Baseline float64 `json:"baseline"`
+	Change   float64 `json:"change"`
+	// PercentChange and Ratio are left out when the baseline is zero
+	PercentChange *float64 `json:"percentChange,omitempty"`
+	Ratio         *float64 `json:"ratio,omitempty"`
+	// Categories are reported for indexes; the average baseline uses the
+	// most frequent category
+	CurrentCategory  string `json:"currentCategory,omitempty"`
+	BaselineCategory string `json:"baselineCategory,omitempty"`
+	CategoryChanged  bool   `json:"categoryChanged,omitempty"`
+}
+
+// BaselineComparison compares current conditions with one baseline
+type BaselineComparison struct {
+	Baseline string `json:"baseline"`
+	// DateTime is the baseline hour, Hours the number of hours averaged
+	DateTime   string        `json:"dateTime,omitempty"`
+	Hours      int           `json:"hours,omitempty"`
+	Missing    bool          `json:"missing,omitempty"`
+	Indexes    []ValueChange `json:"indexes"`
+	Pollutants []ValueChange `json:"pollutants"`
+}
+
+// PeriodChangeOutput defines the output for the period change tool
+type PeriodChangeOutput struct {
+	DateTime    string               `json:"dateTime"`
+	AverageDays int                  `json:"averageDays"`
+	Comparisons []BaselineComparison `json:"comparisons"`
+	Highlights  []string             `json:"highlights"`
+}
+
+// NewPeriodChangeHandler creates a new period change handler with the API key
+func NewPeriodChangeHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input PeriodChangeInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		if input.AverageDays == 0 {
+			input.AverageDays = maxAverageDays
+		}
+		if input.AverageDays < 1 || input.AverageDays > maxAverageDays {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("averageDays must be between 1 and %d", maxAverageDays)}},
+			}, nil
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		location := LatLng{Latitude: input.Latitude, Longitude: input.Longitude}
+		extra := []ExtraComputation{ExtraComputationLocalAQI, ExtraComputationPollutantConcentration}
+		universalAqi := true
+		current, err := client.GetCurrentConditions(CurrentConditionsRequest{
+			Location:          location,
+			ExtraComputations: extra,
+			UniversalAqi:      &universalAqi,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get current conditions: %v", err)}},
+			}, nil
+		}
+
+		// One history request covers the average and the same hour last week
+		at := time.Now().UTC().Truncate(time.Hour)
+		if t, err := time.Parse(time.RFC3339, current.DateTime); err == nil {
+			at = t.UTC().Truncate(time.Hour)
+		}
+		hours := max(input.AverageDays*24, 7*24)
+		history, err := fetchHistoryPeriod(client, location, at.Add(-time.Duration(hours)*time.Hour), at, extra)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get history: %v", err)}},
+			}, nil
+		}
+
+		// Convert concentrations to the requested units
+		convertPollutants(current.Pollutants, target)
+		for i := range history {
+			convertPollutants(history[i].Pollutants, target)
+		}
+
+		now := hourSnapshot{DateTime: at.Format(time.RFC3339), Indexes: current.Indexes, Pollutants: current.Pollutants}
+		output := comparePeriods(now, historySnapshots(history), input.AverageDays, input.Pollutants)
+
+		// Render response in the requested format
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
+
+// comparePeriods compares the current hour with the same hour yesterday and
+// last week and with the average of the last averageDays days of history
+func comparePeriods(current hourSnapshot, history []hourSnapshot, averageDays int, codes []string) *PeriodChangeOutput {
+	at, _ := time.Parse(time.RFC3339, current.DateTime)
+	output := &PeriodChangeOutput{
+		DateTime:    current.DateTime,
+		AverageDays: averageDays,
+		Highlights:  []string{},
+	}
+	keep := func(code string) bool {
+		return len(codes) == 0 || slices.Contains(codes, code)
+	}
+
+	// Same hour on earlier days
+	byHour := map[time.Time]hourSnapshot{}
+	for _, h := range history {
+		if t, err := time.Parse(time.RFC3339, h.DateTime); err == nil {
+			byHour[t.UTC().Truncate(time.Hour)] = h
+		}
+	}
+	for _, b := range []struct {
+		name string
+		ago  time.Duration
+	}{
+		{BaselineYesterday, 24 * time.Hour},
+		{BaselineLastWeek, 7 * 24 * time.Hour},
+	} {
+		then := at.Add(-b.ago)
+		comparison := BaselineComparison{Baseline: b.name, DateTime: then.Format(time.RFC3339), Indexes: []ValueChange{}, Pollutants: []ValueChange{}}
+		h, ok := byHour[then]
+		if !ok {
+			comparison.Missing = true
+			output.Comparisons = append(output.Comparisons, comparison)
+			continue
+		}
+		for _, idx := range current.Indexes {
+			if old := findIndex(h.Indexes, idx.Code); old != nil {
+				change := newValueChange(idx.Code, "", float64(idx.Aqi), float64(old.Aqi))
+				change.CurrentCategory, change.BaselineCategory = idx.Category, old.Category
+				change.CategoryChanged = idx.Category != old.Category
+				comparison.Indexes = append(comparison.Indexes, change)
+			}
+		}
+		for _, p := range current.Pollutants {
+			if p.Concentration == nil || !keep(p.Code) {
+				continue
+			}
+			for _, old := range h.Pollutants {
+				if old.Code == p.Code && old.Concentration != nil {
+					comparison.Pollutants = append(comparison.Pollutants, newValueChange(p.Code, p.Concentration.Units, p.Concentration.Value, old.Concentration.Value))
+				}
+			}
+		}
+		output.Comparisons = append(output.Comparisons, comparison)
+	}
+
+	// Average over the last averageDays days
+	since := at.Add(-time.Duration(averageDays) * 24 * time.Hour)
+	var recent []hourSnapshot
+	for t, h := range byHour {
+		if !t.Before(since) && t.Before(at) {
+			recent = append(recent, h)
+		}
+	}
+	average := BaselineComparison{Baseline: BaselineAverage, Hours: len(recent), Missing: len(recent) == 0, Indexes: []ValueChange{}, Pollutants: []ValueChange{}}
+	indexes := indexSeries(recent)
+	for _, idx := range current.Indexes {
+		if points := indexes[idx.Code]; len(points) > 0 {
+			change := newValueChange(idx.Code, "", float64(idx.Aqi), roundTo(mean(seriesValues(points)), 2))
+			change.CurrentCategory, change.BaselineCategory = idx.Category, usualCategory(recent, idx.Code)
+			change.CategoryChanged = change.BaselineCategory != "" && idx.Category != change.BaselineCategory
+			average.Indexes = append(average.Indexes, change)
+		}
+	}
+	pollutants, _ := pollutantSeries(recent)
+	for _, p := range current.Pollutants {
+		if p.Concentration == nil || !keep(p.Code) {
+			continue
+		}
+		if points := pollutants[p.Code]; len(points) > 0 {
+			average.Pollutants = append(average.Pollutants, newValueChange(p.Code, p.Concentration.Units, p.Concentration.Value, roundTo(mean(seriesValues(points)), 2)))
+		}
+	}
+	output.Comparisons = append(output.Comparisons, average)
+
+	output.Highlights = periodHighlights(output.Comparisons, current.Pollutants, averageDays)
+	return output
+}
+
+// newValueChange computes the change of a value from its baseline
+func newValueChange(code, units string, current, baseline float64) ValueChange {
+	change := ValueChange{
+		Code:     code,
+		Units:    units,
+		Current:  current,
+		Baseline: baseline,
+		Change:   roundTo(current-baseline, 2),
+	}
+	if baseline != 0 {
+		percent := roundTo((current-baseline)/math.Abs(baseline)*100, 1)
+		ratio := roundTo(current/baseline, 2)
+		change.PercentChange, change.Ratio = &percent, &ratio
+	}
+	return change
+}
+
+// usualCategory returns the most frequent category of an index, preferring
+// the earliest seen on ties
+func usualCategory(hours []hourSnapshot, code string) string {
+	counts := map[string]int{}
+	var order []string
+	for _, h := range hours {
+		idx := findIndex(h.Indexes, code)
+		if idx == nil || idx.Category == "" {
+			continue
+		}
+		if counts[idx.Category] == 0 {
+			order = append(order, idx.Category)
+		}
+		counts[idx.Category]++
+	}
+	best := ""
+	for _, category := range order {
+		if counts[category] > counts[best] {
+			best = category
+		}
+	}
+	return best
+}
+
+// periodHighlights describes notable pollutant ratios and index category
+// changes in plain sentences
+func periodHighlights(comparisons []BaselineComparison, pollutants []Pollutant, averageDays int) []string {
+	names := map[string]string{}
+	for _, p := range pollutants {
+		names[p.Code] = p.DisplayName
+	}
+	describe := map[string]string{
+		BaselineYesterday: "the same hour yesterday",
+		BaselineLastWeek:  "the same hour last week",
+		BaselineAverage:   fmt.Sprintf("the %d-day average", averageDays),
+	}
+
+	highlights := []string{}
+	for _, c := range comparisons {
+		for _, p := range c.Pollutants {
+			if p.Ratio == nil {
+				continue
+			}
+			name := names[p.Code]
+			if name == "" {
+				name = strings.ToUpper(p.Code)
+			}
+			switch {
+			case *p.Ratio >= notableRatio:
+				highlights = append(highlights, fmt.Sprintf("%s is %sx %s", name, formatRatio(*p.Ratio), describe[c.Baseline]))
+			case *p.Ratio > 0 && *p.Ratio <= 1/notableRatio:
+				highlights = append(highlights, fmt.Sprintf("%s is %sx lower than %s", name, formatRatio(1 / *p.Ratio), describe[c.Baseline]))
+			}
+		}
+		for _, idx := range c.Indexes {
+			if idx.CategoryChanged {
+				highlights = append(highlights, fmt.Sprintf("%s category is now %q, compared with %q for %s", idx.Code, idx.CurrentCategory, idx.BaselineCategory, describe[c.Baseline]))
+			}
+		}
+	}
+	return highlights
+}
+
+// formatRatio writes a ratio with one decimal, dropping a trailing .0
+func formatRatio(ratio float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", ratio), ".0")
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	PersonalGuidanceToolName        = "get_personal_air_quality_guidance"
+	PersonalGuidanceToolDescription = "Get health guidance tailored to a sensitivity profile for a location and time. Computes the US AQI from pollutant concentrations, rates it against personal risk thresholds lowered for conditions such as asthma, COPD, heart disease, pregnancy, age, outdoor work and vigorous activity, and returns only the recommendations that apply. Uses the profile passed in, or the one registered with set_sensitivity_profile."
+)
+
+var PersonalGuidanceToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"time": map[string]interface{}{
+			"type":        "string",
+			"description": "Time to get guidance for: \"now\" (default), an ISO 8601 or local time, or an expression such as \"now-6h\" or \"tomorrow morning\" in the past 30 days or next 96 hours",
+		},
+		"profile": profileSchema,
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+// PersonalGuidanceInput defines the input for the personal guidance tool
+type PersonalGuidanceInput struct {
+	Latitude     float64             `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude    float64             `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Time         string              `json:"time,omitempty" jsonschema:"description=Time to get guidance for: now (default) or an ISO 8601 time"`
+	Profile      *SensitivityProfile `json:"profile,omitempty" jsonschema:"description=Sensitivity profile (default: the stored profile)"`
+	OutputFormat string              `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// PersonalGuidanceOutput defines the output for the personal guidance tool
+type PersonalGuidanceOutput struct {
+	DateTime string     `json:"dateTime"`
+	Source   TimeSource `json:"source"`
+	*PersonalGuidance
+}
+
+// NewPersonalGuidanceHandler creates a new personal guidance handler with the API key
+func NewPersonalGuidanceHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input PersonalGuidanceInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		profile, err := resolveProfile(request, input.Profile)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if profile == nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "no profile given and none registered, pass profile or call " + SetProfileToolName + " first"}},
+			}, nil
+		}
+
+		// Resolve which endpoint serves the requested time
+		now := time.Now().UTC()
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		at, err := parseTimeInput(input.Time, now, zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		source, err := routeTime(at, now)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		snap, err := fetchSnapshot(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, at, source, []ExtraComputation{
+			ExtraComputationHealthRecommendations,
+			ExtraComputationPollutantConcentration,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get air quality: %v", err)}},
+			}, nil
+		}
+
+		guidance, err := personalGuidance(*profile, snap.Pollutants, snap.HealthRecommendations)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compute personal guidance: %v", err)}},
+			}, nil
+		}
+		output := &PersonalGuidanceOutput{DateTime: snap.DateTime, Source: source, PersonalGuidance: guidance}
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
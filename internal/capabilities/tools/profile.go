@@ -0,0 +1,295 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/aqi"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Age groups of a sensitivity profile
+const (
+	AgeGroupChild      = "child"
+	AgeGroupAdult      = "adult"
+	AgeGroupOlderAdult = "older_adult"
+)
+
+// Activity intensities of a sensitivity profile
+const (
+	ActivityLight    = "light"
+	ActivityModerate = "moderate"
+	ActivityVigorous = "vigorous"
+)
+
+// SensitivityProfile describes how sensitive a person is to air pollution
+type SensitivityProfile struct {
+	AgeGroup          string `json:"ageGroup,omitempty" jsonschema:"description=Age group (child adult older_adult)"`
+	Asthma            bool   `json:"asthma,omitempty" jsonschema:"description=Has asthma"`
+	COPD              bool   `json:"copd,omitempty" jsonschema:"description=Has chronic obstructive pulmonary disease"`
+	HeartDisease      bool   `json:"heartDisease,omitempty" jsonschema:"description=Has heart or cardiovascular disease"`
+	Pregnant          bool   `json:"pregnant,omitempty" jsonschema:"description=Is pregnant"`
+	OutdoorWorker     bool   `json:"outdoorWorker,omitempty" jsonschema:"description=Works outdoors"`
+	ActivityIntensity string `json:"activityIntensity,omitempty" jsonschema:"description=Planned activity intensity (light moderate vigorous)"`
+}
+
+// profileSchema is the input schema of a sensitivity profile
+var profileSchema = map[string]interface{}{
+	"type":        "object",
+	"description": "Sensitivity profile (age group, conditions, activity) to tailor health guidance to",
+	"properties": map[string]interface{}{
+		"ageGroup": map[string]interface{}{
+			"type":        "string",
+			"description": "Age group (child adult older_adult)",
+		},
+		"asthma": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Has asthma",
+		},
+		"copd": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Has chronic obstructive pulmonary disease",
+		},
+		"heartDisease": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Has heart or cardiovascular disease",
+		},
+		"pregnant": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Is pregnant",
+		},
+		"outdoorWorker": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Works outdoors",
+		},
+		"activityIntensity": map[string]interface{}{
+			"type":        "string",
+			"description": "Planned activity intensity (light moderate vigorous)",
+		},
+	},
+}
+
+// Validate checks the enumerated fields of a profile
+func (p SensitivityProfile) Validate() error {
+	switch p.AgeGroup {
+	case "", AgeGroupChild, AgeGroupAdult, AgeGroupOlderAdult:
+	default:
+		return fmt.Errorf("invalid ageGroup %q, expected child, adult or older_adult", p.AgeGroup)
+	}
+	switch p.ActivityIntensity {
+	case "", ActivityLight, ActivityModerate, ActivityVigorous:
+	default:
+		return fmt.Errorf("invalid activityIntensity %q, expected light, moderate or vigorous", p.ActivityIntensity)
+	}
+	return nil
+}
+
+// sensitivityPoints scores how much a profile lowers the AQI at which risk
+// rises. Lung and heart disease weigh most, as in EPA guidance.
+func (p SensitivityProfile) sensitivityPoints() int {
+	points := 0
+	for _, c := range []struct {
+		present bool
+		weight  int
+	}{
+		{p.Asthma, 2},
+		{p.COPD, 2},
+		{p.HeartDisease, 2},
+		{p.Pregnant, 1},
+		{p.AgeGroup == AgeGroupChild, 1},
+		{p.AgeGroup == AgeGroupOlderAdult, 1},
+		{p.OutdoorWorker, 1},
+		{p.ActivityIntensity == ActivityVigorous, 1},
+	} {
+		if c.present {
+			points += c.weight
+		}
+	}
+	return points
+}
+
+// PersonalThresholds are the US AQI values at which personal risk rises
+type PersonalThresholds struct {
+	Moderate int `json:"moderate"`
+	High     int `json:"high"`
+	VeryHigh int `json:"veryHigh"`
+}
+
+// Thresholds starts from the levels for the general population (101, 151,
+// 201) and lowers each by 25 per sensitivity point, so a person with asthma
+// gets the EPA sensitive group levels (51, 101, 151)
+func (p SensitivityProfile) Thresholds() PersonalThresholds {
+	shift := 25 * p.sensitivityPoints()
+	return PersonalThresholds{
+		Moderate: max(26, 101-shift),
+		High:     max(51, 151-shift),
+		VeryHigh: max(101, 201-shift),
+	}
+}
+
+// RiskLevel classifies a US AQI value against the thresholds
+func (t PersonalThresholds) RiskLevel(aqi int) string {
+	switch {
+	case aqi >= t.VeryHigh:
+		return "very high"
+	case aqi >= t.High:
+		return "high"
+	case aqi >= t.Moderate:
+		return "moderate"
+	default:
+		return "low"
+	}
+}
+
+// PersonalRecommendation is a health recommendation for one group
+type PersonalRecommendation struct {
+	Group          string `json:"group"`
+	Recommendation string `json:"recommendation"`
+}
+
+// relevantRecommendations picks the recommendations that apply to a profile,
+// falling back to the general population
+func relevantRecommendations(p SensitivityProfile, recs *HealthRecommendations) []PersonalRecommendation {
+	if recs == nil {
+		return nil
+	}
+	var out []PersonalRecommendation
+	add := func(applies bool, group, text string) {
+		if applies && text != "" {
+			out = append(out, PersonalRecommendation{Group: group, Recommendation: text})
+		}
+	}
+	add(p.Asthma || p.COPD, "lungDiseasePopulation", recs.LungDiseasePopulation)
+	add(p.HeartDisease, "heartDiseasePopulation", recs.HeartDiseasePopulation)
+	add(p.Pregnant, "pregnantWomen", recs.PregnantWomen)
+	add(p.AgeGroup == AgeGroupChild, "children", recs.Children)
+	add(p.AgeGroup == AgeGroupOlderAdult, "elderly", recs.Elderly)
+	add(p.OutdoorWorker || p.ActivityIntensity == ActivityVigorous, "athletes", recs.Athletes)
+	if len(out) == 0 {
+		add(true, "generalPopulation", recs.GeneralPopulation)
+	}
+	return out
+}
+
+// PersonalGuidance is health guidance tailored to a sensitivity profile
+type PersonalGuidance struct {
+	Profile           SensitivityProfile       `json:"profile"`
+	UsAqi             int                      `json:"usAqi"`
+	DominantPollutant string                   `json:"dominantPollutant,omitempty"`
+	RiskLevel         string                   `json:"riskLevel"`
+	Thresholds        PersonalThresholds       `json:"thresholds"`
+	Recommendations   []PersonalRecommendation `json:"recommendations,omitempty"`
+}
+
+// personalGuidance computes the US AQI from pollutant concentrations and rates
+// it against a profile's thresholds
+func personalGuidance(p SensitivityProfile, pollutants []Pollutant, recs *HealthRecommendations) (*PersonalGuidance, error) {
+	concentrations := map[string]aqi.Concentration{}
+	for _, pol := range pollutants {
+		if pol.Concentration != nil {
+			concentrations[pol.Code] = aqi.Concentration{Value: pol.Concentration.Value, Units: units.Unit(pol.Concentration.Units)}
+		}
+	}
+	index, err := aqi.Compute(aqi.StandardUSEPA, concentrations)
+	if err != nil {
+		return nil, err
+	}
+	thresholds := p.Thresholds()
+	return &PersonalGuidance{
+		Profile:           p,
+		UsAqi:             index.Value,
+		DominantPollutant: index.DominantPollutant,
+		RiskLevel:         thresholds.RiskLevel(index.Value),
+		Thresholds:        thresholds,
+		Recommendations:   relevantRecommendations(p, recs),
+	}, nil
+}
+
+// ProfileStore keeps sensitivity profiles per API token or MCP session
+type ProfileStore struct {
+	mu       sync.RWMutex
+	profiles map[string]SensitivityProfile
+}
+
+// NewProfileStore creates an empty profile store
+func NewProfileStore() *ProfileStore {
+	return &ProfileStore{profiles: map[string]SensitivityProfile{}}
+}
+
+// profiles holds the profiles registered through set_sensitivity_profile
+var profiles = NewProfileStore()
+
+// Get returns the profile stored under key
+func (s *ProfileStore) Get(key string) (SensitivityProfile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.profiles[key]
+	return p, ok
+}
+
+// Set stores a profile under key
+func (s *ProfileStore) Set(key string, p SensitivityProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[key] = p
+}
+
+// Delete removes the profile stored under key
+func (s *ProfileStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.profiles, key)
+}
+
+// profileKey identifies who a request belongs to: the bearer token when the
+// client sends one, so profiles survive reconnects, otherwise the MCP session,
+// whose profile is dropped when it closes. It reports false for requests with
+// neither, which would otherwise all share one profile.
+// Tokens are hashed so they are never kept in memory.
+func profileKey(request *mcp.CallToolRequest) (key, scope string, ok bool) {
+	if request.Extra != nil && request.Extra.Header != nil {
+		auth := request.Extra.Header.Get("Authorization")
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok && token != "" {
+			sum := sha256.Sum256([]byte(token))
+			return "token:" + hex.EncodeToString(sum[:]), "token", true
+		}
+	}
+	if id := sessionID(request); id != "" {
+		return "session:" + id, "session", true
+	}
+	return "", "", false
+}
+
+// resolveProfile returns the profile passed with a request, or the stored
+// profile of its caller
+func resolveProfile(request *mcp.CallToolRequest, passed *SensitivityProfile) (*SensitivityProfile, error) {
+	if passed != nil {
+		if err := passed.Validate(); err != nil {
+			return nil, err
+		}
+		return passed, nil
+	}
+	key, _, ok := profileKey(request)
+	if !ok {
+		return nil, nil
+	}
+	if p, ok := profiles.Get(key); ok {
+		return &p, nil
+	}
+	return nil, nil
+}
+
+// appendMissing appends the extra computations not already requested
+func appendMissing(extra []ExtraComputation, add ...ExtraComputation) []ExtraComputation {
+	for _, a := range add {
+		if !slices.Contains(extra, a) {
+			extra = append(extra, a)
+		}
+	}
+	return extra
+}
@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// fieldsDescription documents the fields input shared by data tools
+const fieldsDescription = "Parts of the response to keep (default: all): regionCode, indexes, pollutants and healthRecommendations, or single index fields (indexes.aqi indexes.aqiDisplay indexes.category indexes.dominantPollutant indexes.color indexes.displayName) and pollutant fields (pollutants.concentration pollutants.displayName pollutants.fullName pollutants.additionalInfo). Codes and times are always kept"
+
+// pollutantsFilterDescription documents the pollutants input shared by data tools
+const pollutantsFilterDescription = "Pollutant and index codes to keep, e.g. [\"uaqi\", \"pm25\"] (default: all). Indexes are filtered too when an index code such as uaqi or usa_epa is listed, otherwise all indexes are kept"
+
+// projectionSections are the top-level parts of a response fields can select
+var projectionSections = []string{"regionCode", "indexes", "pollutants", "healthRecommendations"}
+
+// projectionFields are the index and pollutant fields fields can select
+var projectionFields = map[string][]string{
+	"indexes":    {"displayName", "aqi", "aqiDisplay", "color", "category", "dominantPollutant"},
+	"pollutants": {"displayName", "fullName", "concentration", "additionalInfo"},
+}
+
+// Projection selects which parts of a response are returned. A nil
+// Projection keeps everything.
+type Projection struct {
+	// sections maps a kept section to its kept fields; nil fields keep all
+	sections map[string]map[string]bool
+	codes    map[string]bool
+}
+
+// ParseProjection validates fields and pollutants inputs. It returns nil when
+// both are empty.
+func ParseProjection(fields, pollutants []string) (*Projection, error) {
+	if len(fields) == 0 && len(pollutants) == 0 {
+		return nil, nil
+	}
+
+	p := &Projection{}
+	if len(fields) > 0 {
+		p.sections = map[string]map[string]bool{}
+		for _, f := range fields {
+			section, field, nested := strings.Cut(strings.TrimSpace(f), ".")
+			if !slices.Contains(projectionSections, section) {
+				return nil, fmt.Errorf("invalid field %q, expected one of %v or an index or pollutant field", f, projectionSections)
+			}
+			if !nested {
+				p.sections[section] = nil
+				continue
+			}
+			if !slices.Contains(projectionFields[section], field) {
+				return nil, fmt.Errorf("invalid field %q, %s fields are %v", f, section, projectionFields[section])
+			}
+			kept, ok := p.sections[section]
+			if ok && kept == nil {
+				// The whole section was already selected
+				continue
+			}
+			if kept == nil {
+				kept = map[string]bool{}
+				p.sections[section] = kept
+			}
+			kept[field] = true
+		}
+	}
+	if len(pollutants) > 0 {
+		p.codes = map[string]bool{}
+		for _, code := range pollutants {
+			p.codes[strings.ToLower(strings.TrimSpace(code))] = true
+		}
+	}
+	return p, nil
+}
+
+// NeedsConcentrations reports whether the projection asks for pollutants,
+// which the API only returns with the POLLUTANT_CONCENTRATION computation
+func (p *Projection) NeedsConcentrations() bool {
+	if p == nil {
+		return false
+	}
+	if p.sections != nil {
+		_, ok := p.sections["pollutants"]
+		return ok
+	}
+	return p.codes != nil
+}
+
+// keeps reports whether a section is kept
+func (p *Projection) keeps(section string) bool {
+	if p.sections == nil {
+		return true
+	}
+	_, ok := p.sections[section]
+	return ok
+}
+
+// keepsField reports whether a field of a section is kept
+func (p *Projection) keepsField(section, field string) bool {
+	if p.sections == nil {
+		return true
+	}
+	return p.sections[section] == nil || p.sections[section][field]
+}
+
+// Current returns a projected copy of current conditions
+func (p *Projection) Current(resp *CurrentConditionsResponse) *CurrentConditionsResponse {
+	if p == nil || resp == nil {
+		return resp
+	}
+	out := *resp
+	if !p.keeps("regionCode") {
+		out.RegionCode = ""
+	}
+	out.Indexes, out.Pollutants, out.HealthRecommendations = p.hour(resp.Indexes, resp.Pollutants, resp.HealthRecommendations)
+	return &out
+}
+
+// Forecast returns a projected copy of a forecast
+func (p *Projection) Forecast(resp *ForecastResponse) *ForecastResponse {
+	if p == nil || resp == nil {
+		return resp
+	}
+	out := *resp
+	if !p.keeps("regionCode") {
+		out.RegionCode = ""
+	}
+	out.HourlyForecasts = make([]HourlyForecast, len(resp.HourlyForecasts))
+	for i, h := range resp.HourlyForecasts {
+		h.Indexes, h.Pollutants, h.HealthRecommendations = p.hour(h.Indexes, h.Pollutants, h.HealthRecommendations)
+		out.HourlyForecasts[i] = h
+	}
+	return &out
+}
+
+// History returns a projected copy of history
+func (p *Projection) History(resp *HistoryResponse) *HistoryResponse {
+	if p == nil || resp == nil {
+		return resp
+	}
+	out := *resp
+	if !p.keeps("regionCode") {
+		out.RegionCode = ""
+	}
+	out.HoursInfo = make([]HourInfo, len(resp.HoursInfo))
+	for i, h := range resp.HoursInfo {
+		h.Indexes, h.Pollutants, h.HealthRecommendations = p.hour(h.Indexes, h.Pollutants, h.HealthRecommendations)
+		out.HoursInfo[i] = h
+	}
+	return &out
+}
+
+// Timeline returns a projected copy of a stitched timeline
+func (p *Projection) Timeline(hours []TimelineHour) []TimelineHour {
+	if p == nil {
+		return hours
+	}
+	out := make([]TimelineHour, len(hours))
+	for i, h := range hours {
+		h.Indexes, h.Pollutants, h.HealthRecommendations = p.hour(h.Indexes, h.Pollutants, h.HealthRecommendations)
+		out[i] = h
+	}
+	return out
+}
+
+// hour projects the indexes, pollutants and recommendations of one hour into
+// new slices, leaving the originals untouched
+func (p *Projection) hour(indexes []AQI, pollutants []Pollutant, recs *HealthRecommendations) ([]AQI, []Pollutant, *HealthRecommendations) {
+	var outIndexes []AQI
+	if p.keeps("indexes") {
+		filterIndexes := false
+		for _, idx := range indexes {
+			if p.codes[idx.Code] {
+				filterIndexes = true
+				break
+			}
+		}
+		for _, idx := range indexes {
+			if filterIndexes && !p.codes[idx.Code] {
+				continue
+			}
+			kept := AQI{Code: idx.Code}
+			if p.keepsField("indexes", "displayName") {
+				kept.DisplayName = idx.DisplayName
+			}
+			if p.keepsField("indexes", "aqi") {
+				kept.Aqi = idx.Aqi
+			}
+			if p.keepsField("indexes", "aqiDisplay") {
+				kept.AqiDisplay = idx.AqiDisplay
+			}
+			if p.keepsField("indexes", "color") {
+				kept.Color = idx.Color
+			}
+			if p.keepsField("indexes", "category") {
+				kept.Category = idx.Category
+			}
+			if p.keepsField("indexes", "dominantPollutant") {
+				kept.DominantPollutant = idx.DominantPollutant
+			}
+			outIndexes = append(outIndexes, kept)
+		}
+	}
+
+	var outPollutants []Pollutant
+	if p.keeps("pollutants") {
+		for _, pol := range pollutants {
+			if p.codes != nil && !p.codes[pol.Code] {
+				continue
+			}
+			kept := Pollutant{Code: pol.Code}
+			if p.keepsField("pollutants", "displayName") {
+				kept.DisplayName = pol.DisplayName
+			}
+			if p.keepsField("pollutants", "fullName") {
+				kept.FullName = pol.FullName
+			}
+			if p.keepsField("pollutants", "concentration") && pol.Concentration != nil {
+				concentration := *pol.Concentration
+				kept.Concentration = &concentration
+			}
+			if p.keepsField("pollutants", "additionalInfo") {
+				kept.AdditionalInfo = pol.AdditionalInfo
+			}
+			outPollutants = append(outPollutants, kept)
+		}
+	}
+
+	if !p.keeps("healthRecommendations") {
+		recs = nil
+	}
+	return outIndexes, outPollutants, recs
+}
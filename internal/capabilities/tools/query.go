@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	QueryToolName        = "query_air_quality"
+	QueryToolDescription = "Get air quality for a location at any time or interval in the past 30 days or next 96 hours without choosing an endpoint. Past hours come from history, the current hour from current conditions and later hours from the forecast, stitched into one continuous timeline with a source on each hour."
+)
+
+var QueryToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"dateTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Single hour to get (ISO 8601, local time or an expression such as \"now-6h\" or \"tomorrow 9am\", default: now), instead of a period",
+		},
+		"periodStartTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period start time (ISO 8601, local time or an expression such as \"yesterday\", \"last 3 days\" or \"today\" whose interval also sets the end)",
+		},
+		"periodEndTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period end time (ISO 8601, local time or an expression such as \"now+12h\" or \"tomorrow 9am\", default: the end of the current hour, or of the start hour for future starts)",
+		},
+		"extraComputations": map[string]interface{}{
+			"type":        "array",
+			"description": "Additional features to compute",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"fields": map[string]interface{}{
+			"type":        "array",
+			"description": fieldsDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": pollutantsFilterDescription,
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+// QueryInput defines the input for the query tool
+type QueryInput struct {
+	Latitude          float64  `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude         float64  `json:"longitude" jsonschema:"required,description=Location longitude"`
+	DateTime          string   `json:"dateTime,omitempty" jsonschema:"description=Single hour to get (ISO 8601 or local time or an expression)"`
+	PeriodStartTime   string   `json:"periodStartTime,omitempty" jsonschema:"description=Period start time (ISO 8601 or local time or an expression)"`
+	PeriodEndTime     string   `json:"periodEndTime,omitempty" jsonschema:"description=Period end time (ISO 8601 or local time or an expression)"`
+	ExtraComputations []string `json:"extraComputations,omitempty" jsonschema:"description=Additional features to compute"`
+	Units             string   `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	Fields            []string `json:"fields,omitempty" jsonschema:"description=Parts of the response to keep"`
+	Pollutants        []string `json:"pollutants,omitempty" jsonschema:"description=Pollutant and index codes to keep"`
+	OutputFormat      string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// TimelineHour is one hour of a stitched timeline with the endpoint that
+// served it
+type TimelineHour struct {
+	DateTime              string                 `json:"dateTime"`
+	Source                TimeSource             `json:"source"`
+	Indexes               []AQI                  `json:"indexes,omitempty"`
+	Pollutants            []Pollutant            `json:"pollutants,omitempty"`
+	HealthRecommendations *HealthRecommendations `json:"healthRecommendations,omitempty"`
+}
+
+// QueryOutput defines the output for the query tool
+type QueryOutput struct {
+	PeriodStart   string             `json:"periodStart"`
+	PeriodEnd     string             `json:"periodEnd"`
+	HoursBySource map[TimeSource]int `json:"hoursBySource"`
+	Hours         []TimelineHour     `json:"hours"`
+}
+
+// sourcePriority orders the endpoints serving the same hour; observed data
+// wins over forecasts and the live current conditions win over history
+var sourcePriority = map[TimeSource]int{
+	TimeSourceForecast: 0,
+	TimeSourceHistory:  1,
+	TimeSourceCurrent:  2,
+}
+
+// NewQueryHandler creates a new query handler with the API key
+func NewQueryHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input QueryInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Resolve the requested hours in the location's time zone
+		now := time.Now().UTC()
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		start, end, err := resolveQueryPeriod(input, now, zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		projection, err := ParseProjection(input.Fields, input.Pollutants)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		var extra []ExtraComputation
+		for _, comp := range input.ExtraComputations {
+			extra = append(extra, ExtraComputation(comp))
+		}
+		if projection.NeedsConcentrations() {
+			extra = appendMissing(extra, ExtraComputationPollutantConcentration)
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		hours, err := fetchTimeline(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, start, end, now, extra)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		output := &QueryOutput{
+			PeriodStart:   start.Format(time.RFC3339),
+			PeriodEnd:     end.Format(time.RFC3339),
+			HoursBySource: map[TimeSource]int{},
+			Hours:         []TimelineHour{},
+		}
+		for _, h := range hours {
+			// Convert concentrations to the requested units
+			convertPollutants(h.Pollutants, target)
+			output.HoursBySource[h.Source]++
+		}
+
+		// Drop the parts of the response that were not asked for
+		output.Hours = append(output.Hours, projection.Timeline(hours)...)
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
+
+// resolveQueryPeriod turns the dateTime or period arguments into a [start,
+// end) period of whole hours within the reach of the history and forecast
+// endpoints
+func resolveQueryPeriod(input QueryInput, now time.Time, zone *time.Location) (time.Time, time.Time, error) {
+	hourStart := now.Truncate(time.Hour)
+	var start, end time.Time
+	if input.DateTime != "" || (input.PeriodStartTime == "" && input.PeriodEndTime == "") {
+		if input.PeriodStartTime != "" || input.PeriodEndTime != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("give either dateTime or a period, not both")
+		}
+		at, err := parseTimeInput(input.DateTime, now, zone)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = at.UTC().Truncate(time.Hour)
+		end = start.Add(time.Hour)
+	} else {
+		var err error
+		if start, end, err = resolvePeriodInput(input.PeriodStartTime, input.PeriodEndTime, now, zone); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if start.IsZero() {
+			start = now
+		}
+		start = start.UTC().Truncate(time.Hour)
+		if end.IsZero() {
+			// Past starts run through the current hour, future ones cover their hour
+			end = start
+			if end.Before(hourStart) {
+				end = hourStart
+			}
+			end = end.Add(time.Hour)
+		}
+		// Partial hours at the end are included
+		if rounded := end.UTC().Truncate(time.Hour); rounded.Before(end) {
+			end = rounded.Add(time.Hour)
+		} else {
+			end = rounded
+		}
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("period start must be before period end")
+	}
+	if hourStart.Sub(start) > maxHistoryHours*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("period start %s is more than %d hours in the past", start.Format(time.RFC3339), maxHistoryHours)
+	}
+	if end.Sub(hourStart) > (maxForecastHours+1)*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("period end %s is more than %d hours in the future", end.Format(time.RFC3339), maxForecastHours)
+	}
+	return start, end, nil
+}
+
+// fetchTimeline retrieves every hour in [start, end) from the endpoint that
+// serves it: history before the current hour, current conditions for it and
+// the forecast after it. Hours are returned in time order, once each.
+func fetchTimeline(client *Client, location LatLng, start, end, now time.Time, extra []ExtraComputation) ([]TimelineHour, error) {
+	hourStart := now.Truncate(time.Hour)
+	universalAqi := true
+	var hours []TimelineHour
+
+	if start.Before(hourStart) {
+		infos, err := fetchHistoryPeriod(client, location, start, minTime(end, hourStart), extra)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get history: %v", err)
+		}
+		for _, h := range infos {
+			hours = append(hours, TimelineHour{DateTime: h.DateTime, Source: TimeSourceHistory, Indexes: h.Indexes, Pollutants: h.Pollutants, HealthRecommendations: h.HealthRecommendations})
+		}
+	}
+
+	if !start.After(hourStart) && end.After(hourStart) {
+		resp, err := client.GetCurrentConditions(CurrentConditionsRequest{
+			Location:          location,
+			ExtraComputations: extra,
+			UniversalAqi:      &universalAqi,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get current conditions: %v", err)
+		}
+		hours = append(hours, TimelineHour{DateTime: resp.DateTime, Source: TimeSourceCurrent, Indexes: resp.Indexes, Pollutants: resp.Pollutants, HealthRecommendations: resp.HealthRecommendations})
+	}
+
+	if forecastStart := maxTime(start, hourStart.Add(time.Hour)); forecastStart.Before(end) {
+		forecast, err := fetchForecastPeriod(client, location, forecastStart, end, extra)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get forecast: %v", err)
+		}
+		for _, h := range forecast {
+			hours = append(hours, TimelineHour{DateTime: h.DateTime, Source: TimeSourceForecast, Indexes: h.Indexes, Pollutants: h.Pollutants, HealthRecommendations: h.HealthRecommendations})
+		}
+	}
+
+	return stitchTimeline(hours, start, end), nil
+}
+
+// stitchTimeline orders hours by time and keeps one entry per hour within
+// [start, end), preferring the source with the highest priority
+func stitchTimeline(hours []TimelineHour, start, end time.Time) []TimelineHour {
+	byHour := map[time.Time]TimelineHour{}
+	for _, h := range hours {
+		at, err := time.Parse(time.RFC3339, h.DateTime)
+		if err != nil {
+			continue
+		}
+		at = at.UTC().Truncate(time.Hour)
+		if at.Before(start) || !at.Before(end) {
+			continue
+		}
+		if kept, ok := byHour[at]; ok && sourcePriority[kept.Source] >= sourcePriority[h.Source] {
+			continue
+		}
+		h.DateTime = at.Format(time.RFC3339)
+		byHour[at] = h
+	}
+
+	keys := make([]time.Time, 0, len(byHour))
+	for at := range byHour {
+		keys = append(keys, at)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+	timeline := make([]TimelineHour, 0, len(keys))
+	for _, at := range keys {
+		timeline = append(timeline, byHour[at])
+	}
+	return timeline
+}
+
+// minTime returns the earlier of two times
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// maxTime returns the later of two times
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
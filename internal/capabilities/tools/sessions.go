@@ -0,0 +1,18 @@
+package tools
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// sessionID returns the ID of the MCP session a request came from, or an
+// empty string for requests without one
+func sessionID(request *mcp.CallToolRequest) string {
+	if request.Session == nil {
+		return ""
+	}
+	return request.Session.ID()
+}
+
+// SessionClosed drops the state a session kept on the server
+func SessionClosed(id string) {
+	Alerts.DeleteSession(id)
+	profiles.Delete("session:" + id)
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	SetProfileToolName        = "set_sensitivity_profile"
+	SetProfileToolDescription = "Register a personal sensitivity profile (age group, asthma, COPD, heart disease, pregnancy, outdoor work, activity intensity). The profile is stored per API token, or per MCP session until it closes when no token is sent; requests with neither cannot store one. It tailors the health guidance of get_current_air_quality and get_personal_air_quality_guidance. Returns the personal risk thresholds it implies."
+)
+
+var SetProfileToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"profile": profileSchema,
+		"clear": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Remove the stored profile instead of setting one (default: false)",
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+}
+
+// SetProfileInput defines the input for the set profile tool
+type SetProfileInput struct {
+	Profile      SensitivityProfile `json:"profile" jsonschema:"description=Sensitivity profile to store"`
+	Clear        bool               `json:"clear,omitempty" jsonschema:"description=Remove the stored profile (default: false)"`
+	OutputFormat string             `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// SetProfileOutput defines the output for the set profile tool
+type SetProfileOutput struct {
+	Scope      string              `json:"scope"`
+	Cleared    bool                `json:"cleared,omitempty"`
+	Profile    *SensitivityProfile `json:"profile,omitempty"`
+	Thresholds *PersonalThresholds `json:"thresholds,omitempty"`
+}
+
+// NewSetProfileHandler creates a new set profile handler
+func NewSetProfileHandler() func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input SetProfileInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		key, scope, ok := profileKey(request)
+		if !ok {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "no session or bearer token to store the profile under, pass the profile with each request instead"}},
+			}, nil
+		}
+		output := &SetProfileOutput{Scope: scope}
+		if input.Clear {
+			profiles.Delete(key)
+			output.Cleared = true
+		} else {
+			if err := input.Profile.Validate(); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				}, nil
+			}
+			profiles.Set(key, input.Profile)
+			thresholds := input.Profile.Thresholds()
+			output.Profile, output.Thresholds = &input.Profile, &thresholds
+		}
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, nil), nil
+	}
+}
@@ -0,0 +1,361 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timeexpr"
+)
+
+const (
+	// maxForecastHours is how far ahead the forecast endpoint can look
+	maxForecastHours = 96
+	// maxHistoryHours is how far back the history endpoint can look
+	maxHistoryHours = 720
+)
+
+// TimeSource identifies which API endpoint served a piece of data
+type TimeSource string
+
+const (
+	TimeSourceHistory  TimeSource = "history"
+	TimeSourceCurrent  TimeSource = "current"
+	TimeSourceForecast TimeSource = "forecast"
+)
+
+// hourSnapshot is the common shape of a single hour of air quality data,
+// shared by current conditions, hourly forecasts and historical hours
+type hourSnapshot struct {
+	DateTime              string
+	Indexes               []AQI
+	Pollutants            []Pollutant
+	HealthRecommendations *HealthRecommendations
+}
+
+// localTimeLayouts are the accepted time formats without a UTC offset
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// timeInputError describes the accepted forms of a time argument
+const timeInputError = "invalid time %q, expected \"now\", ISO 8601, local time such as 2006-01-02T15:04 or an expression such as now-6h, yesterday, last 3 days or tomorrow morning"
+
+// ResolvedTime echoes the UTC times that time arguments resolved to
+type ResolvedTime struct {
+	DateTime        string `json:"dateTime,omitempty"`
+	PeriodStartTime string `json:"periodStartTime,omitempty"`
+	PeriodEndTime   string `json:"periodEndTime,omitempty"`
+}
+
+// parseTimeRange parses a time argument to the interval it covers. An empty
+// value resolves to now, ISO 8601 and local times to a single moment, and
+// expressions such as "yesterday" or "last 3 days" to their interval with
+// calendar days read in zone. Times without a UTC offset are read in zone
+// and returned in UTC.
+func parseTimeRange(value string, now time.Time, zone *time.Location) (timeexpr.Range, error) {
+	if value == "" {
+		return timeexpr.Range{Start: now, End: now}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return timeexpr.Range{Start: t, End: t}, nil
+	}
+	if zone == nil {
+		zone = time.UTC
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, zone); err == nil {
+			return timeexpr.Range{Start: t.UTC(), End: t.UTC()}, nil
+		}
+	}
+	if r, ok := timeexpr.Parse(value, now, zone); ok {
+		return timeexpr.Range{Start: r.Start.UTC(), End: r.End.UTC()}, nil
+	}
+	return timeexpr.Range{}, fmt.Errorf(timeInputError, value)
+}
+
+// parseTimeInput parses a time argument to the start of the interval it
+// covers, see parseTimeRange
+func parseTimeInput(value string, now time.Time, zone *time.Location) (time.Time, error) {
+	r, err := parseTimeRange(value, now, zone)
+	return r.Start, err
+}
+
+// resolvePeriodInput resolves optional period start and end arguments. The
+// end is the end of the end argument's interval or, when only a start such
+// as "yesterday" is given, of the start's interval. Times left unset are
+// zero.
+func resolvePeriodInput(startTime, endTime string, now time.Time, zone *time.Location) (time.Time, time.Time, error) {
+	var start, end time.Time
+	if startTime != "" {
+		r, err := parseTimeRange(startTime, now, zone)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = r.Start
+		if !r.Instant() {
+			end = r.End
+		}
+	}
+	if endTime != "" {
+		r, err := parseTimeRange(endTime, now, zone)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = r.End
+	}
+	return start, end, nil
+}
+
+// resolveTimeInputs resolves the dateTime and period arguments of the
+// forecast and history tools to UTC. Periods are clamped to [earliest,
+// latest] where those are set. It returns nil when no argument is set.
+func resolveTimeInputs(dateTime, periodStartTime, periodEndTime string, now, earliest, latest time.Time, zone *time.Location) (*ResolvedTime, error) {
+	if dateTime == "" && periodStartTime == "" && periodEndTime == "" {
+		return nil, nil
+	}
+	resolved := &ResolvedTime{}
+	if dateTime != "" {
+		at, err := parseTimeInput(dateTime, now, zone)
+		if err != nil {
+			return nil, err
+		}
+		resolved.DateTime = at.UTC().Format(time.RFC3339)
+	}
+
+	start, end, err := resolvePeriodInput(periodStartTime, periodEndTime, now, zone)
+	if err != nil {
+		return nil, err
+	}
+	if !start.IsZero() {
+		if !earliest.IsZero() && start.Before(earliest) {
+			start = earliest
+		}
+		if !latest.IsZero() && start.After(latest) {
+			start = latest
+		}
+		resolved.PeriodStartTime = start.UTC().Format(time.RFC3339)
+	}
+	if !end.IsZero() {
+		if !earliest.IsZero() && end.Before(earliest) {
+			end = earliest
+		}
+		if !latest.IsZero() && end.After(latest) {
+			end = latest
+		}
+		resolved.PeriodEndTime = end.UTC().Format(time.RFC3339)
+	}
+	return resolved, nil
+}
+
+// routeTime decides which endpoint serves data for t. Anything within the
+// current clock hour is served by current conditions.
+func routeTime(t, now time.Time) (TimeSource, error) {
+	hourStart := now.UTC().Truncate(time.Hour)
+	switch {
+	case t.Before(hourStart):
+		if hourStart.Sub(t) > maxHistoryHours*time.Hour {
+			return "", fmt.Errorf("time %s is more than %d hours in the past", t.Format(time.RFC3339), maxHistoryHours)
+		}
+		return TimeSourceHistory, nil
+	case t.Before(hourStart.Add(time.Hour)):
+		return TimeSourceCurrent, nil
+	default:
+		if t.Sub(hourStart) > maxForecastHours*time.Hour {
+			return "", fmt.Errorf("time %s is more than %d hours in the future", t.Format(time.RFC3339), maxForecastHours)
+		}
+		return TimeSourceForecast, nil
+	}
+}
+
+// fetchSnapshot retrieves a single hour of data for a location from the endpoint
+// selected by source
+func fetchSnapshot(client *Client, location LatLng, t time.Time, source TimeSource, extra []ExtraComputation) (*hourSnapshot, error) {
+	universalAqi := true
+	dateTime := t.UTC().Truncate(time.Hour).Format(time.RFC3339)
+
+	switch source {
+	case TimeSourceCurrent:
+		resp, err := client.GetCurrentConditions(CurrentConditionsRequest{
+			Location:          location,
+			ExtraComputations: extra,
+			UniversalAqi:      &universalAqi,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &hourSnapshot{
+			DateTime:              resp.DateTime,
+			Indexes:               resp.Indexes,
+			Pollutants:            resp.Pollutants,
+			HealthRecommendations: resp.HealthRecommendations,
+		}, nil
+	case TimeSourceForecast:
+		resp, err := FetchForecast(client, ForecastRequest{
+			Location:          location,
+			ExtraComputations: extra,
+			DateTime:          dateTime,
+			UniversalAqi:      &universalAqi,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.HourlyForecasts) == 0 {
+			return nil, fmt.Errorf("no forecast data for %s", dateTime)
+		}
+		h := resp.HourlyForecasts[0]
+		return &hourSnapshot{
+			DateTime:              h.DateTime,
+			Indexes:               h.Indexes,
+			Pollutants:            h.Pollutants,
+			HealthRecommendations: h.HealthRecommendations,
+		}, nil
+	case TimeSourceHistory:
+		resp, err := client.GetHistory(HistoryRequest{
+			Location:          location,
+			ExtraComputations: extra,
+			DateTime:          dateTime,
+			UniversalAqi:      &universalAqi,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.HoursInfo) == 0 {
+			return nil, fmt.Errorf("no historical data for %s", dateTime)
+		}
+		h := resp.HoursInfo[0]
+		return &hourSnapshot{
+			DateTime:              h.DateTime,
+			Indexes:               h.Indexes,
+			Pollutants:            h.Pollutants,
+			HealthRecommendations: h.HealthRecommendations,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown time source %q", source)
+	}
+}
+
+// findIndex returns the index with the given code, or nil if absent
+func findIndex(indexes []AQI, code string) *AQI {
+	for i := range indexes {
+		if indexes[i].Code == code {
+			return &indexes[i]
+		}
+	}
+	return nil
+}
+
+// localIndex returns the first non-universal index, or nil if absent
+func localIndex(indexes []AQI) *AQI {
+	for i := range indexes {
+		if indexes[i].Code != universalAqiCode {
+			return &indexes[i]
+		}
+	}
+	return nil
+}
+
+// universalAqiCode is the index code Google uses for the Universal AQI
+const universalAqiCode = "uaqi"
+
+// maxHistoryPageSize is the largest page the history endpoint returns
+const maxHistoryPageSize = 168
+
+// resolveHistoryPeriod turns either an explicit start/end or a number of past
+// hours into a [start, end) period that the history endpoint can serve. Times
+// without a UTC offset and expressions such as "yesterday" are read in zone.
+func resolveHistoryPeriod(hours int, startTime, endTime string, now time.Time, zone *time.Location) (time.Time, time.Time, error) {
+	end := now.UTC().Truncate(time.Hour)
+	if startTime == "" && endTime == "" {
+		if hours == 0 {
+			hours = 24
+		}
+		if hours < 1 || hours > maxHistoryHours {
+			return time.Time{}, time.Time{}, fmt.Errorf("hours must be between 1 and %d", maxHistoryHours)
+		}
+		return end.Add(-time.Duration(hours) * time.Hour), end, nil
+	}
+
+	start, rangeEnd, err := resolvePeriodInput(startTime, endTime, now, zone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if start.IsZero() {
+		start = now
+	}
+	if !rangeEnd.IsZero() {
+		end = rangeEnd
+		if endTime == "" && end.After(now) {
+			// The interval of a start such as "today" ends at now
+			end = now
+		}
+	}
+	start, end = start.UTC().Truncate(time.Hour), end.UTC().Truncate(time.Hour)
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("period start must be before period end")
+	}
+	if now.Sub(start) > maxHistoryHours*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("period start is more than %d hours in the past", maxHistoryHours)
+	}
+	if end.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("period end must not be in the future")
+	}
+	return start, end, nil
+}
+
+// fetchForecastPeriod retrieves every forecast hour starting in [start, end),
+// where start and end are whole hours, and archives them like FetchForecast
+func fetchForecastPeriod(client *Client, location LatLng, start, end time.Time, extra []ExtraComputation) ([]HourlyForecast, error) {
+	hours, err := forecastPeriod(client, location, start, end, extra)
+	if err != nil {
+		return nil, err
+	}
+	archiveForecast(location, hours)
+	return hours, nil
+}
+
+// forecastPeriod retrieves every forecast hour starting in [start, end)
+// without archiving it. The API reads a period like google.type.Interval,
+// with the start included and the end excluded, so end is passed as is;
+// hours outside the period are dropped all the same.
+func forecastPeriod(client *Client, location LatLng, start, end time.Time, extra []ExtraComputation) ([]HourlyForecast, error) {
+	universalAqi := true
+	hours, err := client.GetForecastHours(ForecastRequest{
+		Location:          location,
+		ExtraComputations: extra,
+		PageSize:          maxForecastHours,
+		UniversalAqi:      &universalAqi,
+		Period: &Interval{
+			StartTime: start.UTC().Format(time.RFC3339),
+			EndTime:   end.UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	within := hours[:0]
+	for _, h := range hours {
+		if t, err := time.Parse(time.RFC3339, h.DateTime); err == nil && !t.Before(start) && t.Before(end) {
+			within = append(within, h)
+		}
+	}
+	return within, nil
+}
+
+// fetchHistoryPeriod retrieves every historical hour in [start, end)
+func fetchHistoryPeriod(client *Client, location LatLng, start, end time.Time, extra []ExtraComputation) ([]HourInfo, error) {
+	universalAqi := true
+	return client.GetHistoryHours(HistoryRequest{
+		Location:          location,
+		ExtraComputations: extra,
+		PageSize:          maxHistoryPageSize,
+		UniversalAqi:      &universalAqi,
+		Period: &Interval{
+			StartTime: start.Format(time.RFC3339),
+			EndTime:   end.Format(time.RFC3339),
+		},
+	})
+}
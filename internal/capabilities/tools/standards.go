@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+// AveragingPeriod is the time over which concentrations are averaged before
+// comparing them with a limit
+type AveragingPeriod string
+
+const (
+	// Averaging1h compares every hourly value
+	Averaging1h AveragingPeriod = "1h"
+	// Averaging8h compares the rolling mean of the 8 hours ending at each hour;
+	// exceedances count the days whose maximum 8-hour mean is above the limit
+	Averaging8h AveragingPeriod = "8h"
+	// Averaging24h compares daily means over the location's calendar days
+	Averaging24h AveragingPeriod = "24h"
+)
+
+// StandardLimit is a single concentration limit of an air quality standard
+type StandardLimit struct {
+	Pollutant string          `json:"pollutant"`
+	Averaging AveragingPeriod `json:"averaging"`
+	Value     float64         `json:"value"`
+	Units     units.Unit      `json:"units"`
+	// AllowedPerYear is how many exceedances the standard tolerates per year:
+	// hours for 1-hour limits and days for 8-hour and 24-hour limits
+	AllowedPerYear int `json:"allowedPerYear,omitempty"`
+}
+
+// AirQualityStandard is a named set of short-term concentration limits
+type AirQualityStandard struct {
+	Code   string          `json:"code"`
+	Name   string          `json:"name"`
+	Limits []StandardLimit `json:"limits"`
+}
+
+// Standards is the bundled catalog of air quality standards. Only limits with
+// averaging periods of 24 hours or less are included, since history covers at
+// most 30 days.
+var Standards = map[string]AirQualityStandard{
+	"WHO_2021": {
+		Code: "WHO_2021",
+		Name: "WHO Air Quality Guidelines (2021)",
+		Limits: []StandardLimit{
+			{Pollutant: "pm25", Averaging: Averaging24h, Value: 15, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "pm10", Averaging: Averaging24h, Value: 45, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "o3", Averaging: Averaging8h, Value: 100, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "no2", Averaging: Averaging1h, Value: 200, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "no2", Averaging: Averaging24h, Value: 25, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "so2", Averaging: Averaging24h, Value: 40, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "co", Averaging: Averaging1h, Value: 35000, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "co", Averaging: Averaging8h, Value: 10000, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "co", Averaging: Averaging24h, Value: 4000, Units: units.MicrogramsPerCubicMeter},
+		},
+	},
+	"EU": {
+		Code: "EU",
+		Name: "EU Ambient Air Quality Directive 2008/50/EC",
+		Limits: []StandardLimit{
+			{Pollutant: "pm10", Averaging: Averaging24h, Value: 50, Units: units.MicrogramsPerCubicMeter, AllowedPerYear: 35},
+			{Pollutant: "o3", Averaging: Averaging8h, Value: 120, Units: units.MicrogramsPerCubicMeter, AllowedPerYear: 25},
+			{Pollutant: "no2", Averaging: Averaging1h, Value: 200, Units: units.MicrogramsPerCubicMeter, AllowedPerYear: 18},
+			{Pollutant: "so2", Averaging: Averaging1h, Value: 350, Units: units.MicrogramsPerCubicMeter, AllowedPerYear: 24},
+			{Pollutant: "so2", Averaging: Averaging24h, Value: 125, Units: units.MicrogramsPerCubicMeter, AllowedPerYear: 3},
+			{Pollutant: "co", Averaging: Averaging8h, Value: 10000, Units: units.MicrogramsPerCubicMeter},
+		},
+	},
+	"US_NAAQS": {
+		Code: "US_NAAQS",
+		Name: "US National Ambient Air Quality Standards",
+		Limits: []StandardLimit{
+			{Pollutant: "pm25", Averaging: Averaging24h, Value: 35, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "pm10", Averaging: Averaging24h, Value: 150, Units: units.MicrogramsPerCubicMeter, AllowedPerYear: 1},
+			{Pollutant: "o3", Averaging: Averaging8h, Value: 70, Units: units.PartsPerBillion},
+			{Pollutant: "no2", Averaging: Averaging1h, Value: 100, Units: units.PartsPerBillion},
+			{Pollutant: "so2", Averaging: Averaging1h, Value: 75, Units: units.PartsPerBillion},
+			{Pollutant: "co", Averaging: Averaging1h, Value: 35000, Units: units.PartsPerBillion, AllowedPerYear: 1},
+			{Pollutant: "co", Averaging: Averaging8h, Value: 9000, Units: units.PartsPerBillion, AllowedPerYear: 1},
+		},
+	},
+	"IN_NAAQS": {
+		Code: "IN_NAAQS",
+		Name: "India National Ambient Air Quality Standards (2009)",
+		Limits: []StandardLimit{
+			{Pollutant: "pm25", Averaging: Averaging24h, Value: 60, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "pm10", Averaging: Averaging24h, Value: 100, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "o3", Averaging: Averaging1h, Value: 180, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "o3", Averaging: Averaging8h, Value: 100, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "no2", Averaging: Averaging24h, Value: 80, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "so2", Averaging: Averaging24h, Value: 80, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "co", Averaging: Averaging1h, Value: 4000, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "co", Averaging: Averaging8h, Value: 2000, Units: units.MicrogramsPerCubicMeter},
+			{Pollutant: "nh3", Averaging: Averaging24h, Value: 400, Units: units.MicrogramsPerCubicMeter},
+		},
+	},
+}
+
+// StandardCodes returns the codes of all bundled standards in sorted order
+func StandardCodes() []string {
+	return sortedKeys(Standards)
+}
+
+// ParseStandard looks up a standard by code
+func ParseStandard(code string) (AirQualityStandard, error) {
+	standard, ok := Standards[code]
+	if !ok {
+		return AirQualityStandard{}, fmt.Errorf("unknown standard %q, expected one of %v", code, StandardCodes())
+	}
+	return standard, nil
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// seriesPoint is one hourly value of an index or pollutant
+type seriesPoint struct {
+	At    time.Time
+	Value float64
+}
+
+// SummaryStats summarizes a set of values
+type SummaryStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+}
+
+// historySnapshots converts historical hours to snapshots
+func historySnapshots(hours []HourInfo) []hourSnapshot {
+	snapshots := make([]hourSnapshot, 0, len(hours))
+	for _, h := range hours {
+		snapshots = append(snapshots, hourSnapshot{
+			DateTime:              h.DateTime,
+			Indexes:               h.Indexes,
+			Pollutants:            h.Pollutants,
+			HealthRecommendations: h.HealthRecommendations,
+		})
+	}
+	return snapshots
+}
+
+// pollutantSeries extracts time-ordered hourly concentrations per pollutant code
+// along with the units reported for each pollutant
+func pollutantSeries(hours []hourSnapshot) (map[string][]seriesPoint, map[string]string) {
+	series := map[string][]seriesPoint{}
+	units := map[string]string{}
+	for _, h := range hours {
+		at, err := time.Parse(time.RFC3339, h.DateTime)
+		if err != nil {
+			continue
+		}
+		for _, p := range h.Pollutants {
+			if p.Concentration == nil {
+				continue
+			}
+			series[p.Code] = append(series[p.Code], seriesPoint{At: at, Value: p.Concentration.Value})
+			units[p.Code] = p.Concentration.Units
+		}
+	}
+	for code := range series {
+		sortSeries(series[code])
+	}
+	return series, units
+}
+
+// indexSeries extracts time-ordered hourly values per index code
+func indexSeries(hours []hourSnapshot) map[string][]seriesPoint {
+	series := map[string][]seriesPoint{}
+	for _, h := range hours {
+		at, err := time.Parse(time.RFC3339, h.DateTime)
+		if err != nil {
+			continue
+		}
+		for _, idx := range h.Indexes {
+			series[idx.Code] = append(series[idx.Code], seriesPoint{At: at, Value: float64(idx.Aqi)})
+		}
+	}
+	for code := range series {
+		sortSeries(series[code])
+	}
+	return series
+}
+
+// sortSeries orders points by time
+func sortSeries(points []seriesPoint) {
+	sort.Slice(points, func(i, j int) bool { return points[i].At.Before(points[j].At) })
+}
+
+// seriesValues returns the values of a series
+func seriesValues(points []seriesPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// mean returns the arithmetic mean of values, or 0 for none
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns the p-th percentile (0-100) of sorted values using linear
+// interpolation between closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+// summarize computes summary statistics for values
+func summarize(values []float64) SummaryStats {
+	if len(values) == 0 {
+		return SummaryStats{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return SummaryStats{
+		Count: len(sorted),
+		Mean:  roundTo(mean(sorted), 2),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		P50:   roundTo(percentile(sorted, 50), 2),
+		P90:   roundTo(percentile(sorted, 90), 2),
+		P95:   roundTo(percentile(sorted, 95), 2),
+	}
+}
+
+// median returns the median of values, or 0 for none
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentile(sorted, 50)
+}
+
+// stddev returns the sample standard deviation of values, or 0 for fewer than two
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// TileSize is the width and height in pixels of a heatmap tile
+	TileSize = 256
+	// MaxHeatmapZoom is the highest zoom level served by the heatmap endpoint
+	MaxHeatmapZoom = 16
+	// maxMercatorLatitude is the latitude at which Web Mercator becomes square
+	maxMercatorLatitude = 85.05112878
+)
+
+// TileBounds is the geographic bounding box of a tile in degrees
+type TileBounds struct {
+	North float64 `json:"north"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	West  float64 `json:"west"`
+}
+
+// TilePixel is a pixel position inside a tile, measured from the top-left corner
+type TilePixel struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// HeatmapTileInfo describes where a heatmap tile sits on the map
+type HeatmapTileInfo struct {
+	MapType string     `json:"mapType"`
+	Zoom    int        `json:"zoom"`
+	X       int        `json:"x"`
+	Y       int        `json:"y"`
+	Bounds  TileBounds `json:"bounds"`
+	// Point is the pixel position of the requested latitude/longitude, if any
+	Point *TilePixel `json:"point,omitempty"`
+}
+
+// worldPixel projects a latitude/longitude to Web Mercator pixel coordinates at
+// the given zoom level
+func worldPixel(lat, lng float64, zoom int) (float64, float64) {
+	lat = math.Max(-maxMercatorLatitude, math.Min(maxMercatorLatitude, lat))
+	scale := float64(TileSize) * math.Exp2(float64(zoom))
+	x := (lng + 180) / 360 * scale
+	sinLat := math.Sin(lat * math.Pi / 180)
+	y := (0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)) * scale
+	return x, y
+}
+
+// worldPixelToLatLng is the inverse of worldPixel
+func worldPixelToLatLng(x, y float64, zoom int) (float64, float64) {
+	scale := float64(TileSize) * math.Exp2(float64(zoom))
+	lng := x/scale*360 - 180
+	n := math.Pi - 2*math.Pi*y/scale
+	lat := 180 / math.Pi * math.Atan(math.Sinh(n))
+	return lat, lng
+}
+
+// LatLngToTile returns the tile containing the given point at the zoom level and
+// the pixel position of the point inside that tile
+func LatLngToTile(lat, lng float64, zoom int) (int, int, TilePixel) {
+	px, py := worldPixel(lat, lng, zoom)
+	maxPixel := float64(TileSize)*math.Exp2(float64(zoom)) - 1
+	px = math.Max(0, math.Min(maxPixel, px))
+	py = math.Max(0, math.Min(maxPixel, py))
+	x, y := int(px)/TileSize, int(py)/TileSize
+	return x, y, TilePixel{X: int(px) - x*TileSize, Y: int(py) - y*TileSize}
+}
+
+// TileBoundsFor returns the geographic bounding box of a tile
+func TileBoundsFor(zoom, x, y int) TileBounds {
+	north, west := worldPixelToLatLng(float64(x*TileSize), float64(y*TileSize), zoom)
+	south, east := worldPixelToLatLng(float64((x+1)*TileSize), float64((y+1)*TileSize), zoom)
+	return TileBounds{North: north, South: south, East: east, West: west}
+}
+
+// ValidateLatLng checks that a point lies within the area Web Mercator tiles
+// cover
+func ValidateLatLng(lat, lng float64) error {
+	if lat < -maxMercatorLatitude || lat > maxMercatorLatitude {
+		return fmt.Errorf("latitude must be between %.4f and %.4f", -maxMercatorLatitude, maxMercatorLatitude)
+	}
+	if lng < -180 || lng > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// ValidateTile checks that the zoom level and tile coordinates are in range
+func ValidateTile(zoom, x, y int) error {
+	if zoom < 0 || zoom > MaxHeatmapZoom {
+		return fmt.Errorf("zoom must be between 0 and %d", MaxHeatmapZoom)
+	}
+	n := 1 << zoom
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return fmt.Errorf("tile coordinates must be between 0 and %d at zoom %d", n-1, zoom)
+	}
+	return nil
+}
@@ -1,14 +1,38 @@
 package tools
 
 import (
+	"context"
+	"log"
+
 	"github.com/akshaygalande/google-air-quality-mcp/internal/config"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/forecasts"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// RegisterAll registers all tools with the MCP server
-func RegisterAll(server *mcp.Server) {
+// RegisterAll registers all tools with the MCP server. Background work such
+// as alert polling stops when ctx is done.
+func RegisterAll(ctx context.Context, server *mcp.Server) {
 	// Load configuration to get API key
 	cfg := config.LoadConfig()
+	units.Reference = units.Conditions{TemperatureC: cfg.UnitsTemperatureC, PressureHPa: cfg.UnitsPressureHPa}
+
+	// Archive fetched forecasts for forecast_skill
+	if cfg.ForecastStoreDir != "" {
+		forecastArchive = forecasts.NewStore(cfg.ForecastStoreDir, cfg.ForecastRetention)
+	}
+
+	// Keep exports downloadable for the configured time and within the
+	// configured memory
+	Exports.SetLimits(cfg.ExportTTL, cfg.ExportMaxEntries, cfg.ExportMaxMB<<20)
+
+	// Poll alerts in the background and notify subscribed sessions
+	Alerts.SetLimits(cfg.AlertTTL, cfg.AlertMaxPerSession)
+	Alerts.Start(ctx, NewClient(cfg.APIKey), cfg.AlertPollInterval, func(uri string) {
+		if err := server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+			log.Printf("Failed to notify subscribers of %s: %v", uri, err)
+		}
+	})
 
 	// Register Air Quality API tools
 	server.AddTool(&mcp.Tool{
@@ -34,4 +58,118 @@ func RegisterAll(server *mcp.Server) {
 		Description: HeatmapToolDescription,
 		InputSchema: HeatmapToolSchema,
 	}, NewHeatmapHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        CompareToolName,
+		Description: CompareToolDescription,
+		InputSchema: CompareToolSchema,
+	}, NewCompareHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        BestWindowToolName,
+		Description: BestWindowToolDescription,
+		InputSchema: BestWindowToolSchema,
+	}, NewBestWindowHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        MosaicToolName,
+		Description: MosaicToolDescription,
+		InputSchema: MosaicToolSchema,
+	}, NewMosaicHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        HeatmapSampleToolName,
+		Description: HeatmapSampleToolDescription,
+		InputSchema: HeatmapSampleToolSchema,
+	}, NewHeatmapSampleHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        HistorySummaryToolName,
+		Description: HistorySummaryToolDescription,
+		InputSchema: HistorySummaryToolSchema,
+	}, NewHistorySummaryHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        ExceedanceToolName,
+		Description: ExceedanceToolDescription,
+		InputSchema: ExceedanceToolSchema,
+	}, NewExceedanceHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        ComputeAqiToolName,
+		Description: ComputeAqiToolDescription,
+		InputSchema: ComputeAqiToolSchema,
+	}, NewComputeAqiHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        NowCastToolName,
+		Description: NowCastToolDescription,
+		InputSchema: NowCastToolSchema,
+	}, NewNowCastHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        SetProfileToolName,
+		Description: SetProfileToolDescription,
+		InputSchema: SetProfileToolSchema,
+	}, NewSetProfileHandler())
+
+	server.AddTool(&mcp.Tool{
+		Name:        PersonalGuidanceToolName,
+		Description: PersonalGuidanceToolDescription,
+		InputSchema: PersonalGuidanceToolSchema,
+	}, NewPersonalGuidanceHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        CreateAlertToolName,
+		Description: CreateAlertToolDescription,
+		InputSchema: CreateAlertToolSchema,
+	}, NewCreateAlertHandler())
+
+	server.AddTool(&mcp.Tool{
+		Name:        ListAlertsToolName,
+		Description: ListAlertsToolDescription,
+		InputSchema: ListAlertsToolSchema,
+	}, NewListAlertsHandler())
+
+	server.AddTool(&mcp.Tool{
+		Name:        DeleteAlertToolName,
+		Description: DeleteAlertToolDescription,
+		InputSchema: DeleteAlertToolSchema,
+	}, NewDeleteAlertHandler())
+
+	server.AddTool(&mcp.Tool{
+		Name:        TrendToolName,
+		Description: TrendToolDescription,
+		InputSchema: TrendToolSchema,
+	}, NewTrendHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        ForecastSkillToolName,
+		Description: ForecastSkillToolDescription,
+		InputSchema: ForecastSkillToolSchema,
+	}, NewForecastSkillHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        ExportToolName,
+		Description: ExportToolDescription,
+		InputSchema: ExportToolSchema,
+	}, NewExportHandler(cfg.APIKey, cfg.PublicBaseURL))
+
+	server.AddTool(&mcp.Tool{
+		Name:        QueryToolName,
+		Description: QueryToolDescription,
+		InputSchema: QueryToolSchema,
+	}, NewQueryHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        PeriodChangeToolName,
+		Description: PeriodChangeToolDescription,
+		InputSchema: PeriodChangeToolSchema,
+	}, NewPeriodChangeHandler(cfg.APIKey))
+
+	server.AddTool(&mcp.Tool{
+		Name:        PatternsToolName,
+		Description: PatternsToolDescription,
+		InputSchema: PatternsToolSchema,
+	}, NewPatternsHandler(cfg.APIKey))
 }
@@ -0,0 +1,527 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/render"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/timezone"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	TrendToolName        = "analyze_air_quality_trend"
+	TrendToolDescription = "Analyze whether air quality at a location is getting better or worse over a past period. Computes linear and Theil–Sen trends with a Mann–Kendall significance test on daily means (five or more days are needed for a significant trend) per pollutant and for the Universal AQI, detects spikes against a rolling baseline (robust MAD score or z-score), and groups spike hours into labelled episodes with start, end and magnitude."
+)
+
+var TrendToolSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"latitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location latitude",
+		},
+		"longitude": map[string]interface{}{
+			"type":        "number",
+			"description": "Location longitude",
+		},
+		"hours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of past hours to analyze (default: 168 max: 720)",
+		},
+		"periodStartTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period start time (ISO 8601, local time or an expression such as \"yesterday\", \"last 3 days\" or \"last night\" whose interval also sets the end), instead of hours",
+		},
+		"periodEndTime": map[string]interface{}{
+			"type":        "string",
+			"description": "Period end time (ISO 8601, local time or an expression such as \"now-6h\" or \"yesterday 18:00\", default: now)",
+		},
+		"pollutants": map[string]interface{}{
+			"type":        "array",
+			"description": "Pollutant codes to analyze (default: all reported)",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+		},
+		"spikeMethod": map[string]interface{}{
+			"type":        "string",
+			"description": "Spike score against the rolling baseline (mad zscore, default: mad)",
+		},
+		"spikeThreshold": map[string]interface{}{
+			"type":        "number",
+			"description": "Score at or above which an hour is a spike (default: 3.5 for mad, 3 for zscore)",
+		},
+		"baselineHours": map[string]interface{}{
+			"type":        "integer",
+			"description": "Length of the rolling baseline preceding each hour (default: 24)",
+		},
+		"units": map[string]interface{}{
+			"type":        "string",
+			"description": unitsDescription,
+		},
+		"outputFormat": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+		},
+	},
+	"required": []interface{}{"latitude", "longitude"},
+}
+
+const (
+	// SpikeMethodMAD scores hours by their distance from the baseline median in
+	// median absolute deviations, which outliers in the baseline barely move
+	SpikeMethodMAD = "mad"
+	// SpikeMethodZScore scores hours by standard deviations from the baseline mean
+	SpikeMethodZScore = "zscore"
+
+	// minBaselineHours is the number of baseline hours a spike score needs
+	minBaselineHours = 6
+	// stableChangeRatio is the change over the period, relative to the mean,
+	// below which a trend is reported as stable
+	stableChangeRatio = 0.1
+	// trendSignificance is the Mann–Kendall p-value below which a trend is significant
+	trendSignificance = 0.05
+)
+
+// TrendInput defines the input for the trend analysis tool
+type TrendInput struct {
+	Latitude        float64  `json:"latitude" jsonschema:"required,description=Location latitude"`
+	Longitude       float64  `json:"longitude" jsonschema:"required,description=Location longitude"`
+	Hours           int      `json:"hours,omitempty" jsonschema:"description=Number of past hours to analyze (default: 168 max: 720)"`
+	PeriodStartTime string   `json:"periodStartTime,omitempty" jsonschema:"description=Period start time (ISO 8601 or local time)"`
+	PeriodEndTime   string   `json:"periodEndTime,omitempty" jsonschema:"description=Period end time (ISO 8601 or local time)"`
+	Pollutants      []string `json:"pollutants,omitempty" jsonschema:"description=Pollutant codes to analyze"`
+	SpikeMethod     string   `json:"spikeMethod,omitempty" jsonschema:"description=Spike score (mad zscore)"`
+	SpikeThreshold  float64  `json:"spikeThreshold,omitempty" jsonschema:"description=Score at or above which an hour is a spike"`
+	BaselineHours   int      `json:"baselineHours,omitempty" jsonschema:"description=Length of the rolling baseline (default: 24)"`
+	Units           string   `json:"units,omitempty" jsonschema:"description=Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3)"`
+	OutputFormat    string   `json:"outputFormat,omitempty" jsonschema:"description=Response format (json compact-json markdown summary)"`
+}
+
+// LinearTrend is an ordinary least squares fit of value against time
+type LinearTrend struct {
+	SlopePerDay float64 `json:"slopePerDay"`
+	RSquared    float64 `json:"rSquared"`
+}
+
+// MannKendall is the result of the Mann–Kendall monotonic trend test, run on
+// daily means
+type MannKendall struct {
+	Days        int     `json:"days"`
+	S           int     `json:"s"`
+	Tau         float64 `json:"tau"`
+	Z           float64 `json:"z"`
+	PValue      float64 `json:"pValue"`
+	Significant bool    `json:"significant"`
+}
+
+// TrendEstimate describes the trend of one series over the period
+type TrendEstimate struct {
+	Linear        LinearTrend `json:"linear"`
+	TheilSen      float64     `json:"theilSenSlopePerDay"`
+	MannKendall   MannKendall `json:"mannKendall"`
+	Change        float64     `json:"changeOverPeriod"`
+	PercentChange float64     `json:"percentChange"`
+	Direction     string      `json:"direction"`
+	AirQuality    string      `json:"airQuality"`
+}
+
+// SpikeEpisode is a run of consecutive spike hours
+type SpikeEpisode struct {
+	StartTime string  `json:"startTime"`
+	EndTime   string  `json:"endTime"`
+	Hours     int     `json:"hours"`
+	Peak      float64 `json:"peak"`
+	PeakTime  string  `json:"peakTime"`
+	PeakScore float64 `json:"peakScore"`
+	Baseline  float64 `json:"baseline"`
+	Magnitude float64 `json:"magnitude"`
+	Label     string  `json:"label"`
+}
+
+// SeriesTrend is the trend and spike analysis of one pollutant or index
+type SeriesTrend struct {
+	Code       string         `json:"code"`
+	Kind       string         `json:"kind"`
+	Units      string         `json:"units,omitempty"`
+	Stats      SummaryStats   `json:"stats"`
+	Trend      *TrendEstimate `json:"trend,omitempty"`
+	SpikeHours int            `json:"spikeHours"`
+	Episodes   []SpikeEpisode `json:"episodes"`
+}
+
+// TrendOutput defines the output for the trend analysis tool
+type TrendOutput struct {
+	PeriodStart    string        `json:"periodStart"`
+	PeriodEnd      string        `json:"periodEnd"`
+	SpikeMethod    string        `json:"spikeMethod"`
+	SpikeThreshold float64       `json:"spikeThreshold"`
+	BaselineHours  int           `json:"baselineHours"`
+	Series         []SeriesTrend `json:"series"`
+}
+
+// NewTrendHandler creates a new trend analysis handler with the API key
+func NewTrendHandler(apiKey string) func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Parse input from request arguments
+		var input TrendInput
+		if request.Params.Arguments != nil {
+			// Convert map to JSON and back to struct
+			jsonData, err := json.Marshal(request.Params.Arguments)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal arguments: %v", err)}},
+				}, nil
+			}
+			if err := json.Unmarshal(jsonData, &input); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Invalid input: %v", err)}},
+				}, nil
+			}
+		}
+
+		outputFormat, err := render.ParseFormat(input.OutputFormat)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		if input.Hours == 0 && input.PeriodStartTime == "" {
+			input.Hours = 168
+		}
+		switch input.SpikeMethod {
+		case "":
+			input.SpikeMethod = SpikeMethodMAD
+		case SpikeMethodMAD, SpikeMethodZScore:
+		default:
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid spikeMethod %q, expected mad or zscore", input.SpikeMethod)}},
+			}, nil
+		}
+		if input.SpikeThreshold <= 0 {
+			input.SpikeThreshold = 3.5
+			if input.SpikeMethod == SpikeMethodZScore {
+				input.SpikeThreshold = 3
+			}
+		}
+		if input.BaselineHours <= 0 {
+			input.BaselineHours = 24
+		}
+		if input.BaselineHours < minBaselineHours {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("baselineHours must be at least %d", minBaselineHours)}},
+			}, nil
+		}
+
+		zone := timezone.Lookup(input.Latitude, input.Longitude)
+		start, end, err := resolveHistoryPeriod(input.Hours, input.PeriodStartTime, input.PeriodEndTime, time.Now().UTC(), zone)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		target, err := parseUnitsInput(input.Units)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+
+		// Call API
+		client := NewClient(apiKey)
+		hours, err := fetchHistoryPeriod(client, LatLng{Latitude: input.Latitude, Longitude: input.Longitude}, start, end, []ExtraComputation{
+			ExtraComputationPollutantConcentration,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get history: %v", err)}},
+			}, nil
+		}
+
+		// Convert concentrations to the requested units
+		for i := range hours {
+			convertPollutants(hours[i].Pollutants, target)
+		}
+
+		output := &TrendOutput{
+			PeriodStart:    start.Format(time.RFC3339),
+			PeriodEnd:      end.Format(time.RFC3339),
+			SpikeMethod:    input.SpikeMethod,
+			SpikeThreshold: input.SpikeThreshold,
+			BaselineHours:  input.BaselineHours,
+			Series:         []SeriesTrend{},
+		}
+		baseline := time.Duration(input.BaselineHours) * time.Hour
+		snapshots := historySnapshots(hours)
+		if points := indexSeries(snapshots)[universalAqiCode]; len(points) > 0 {
+			output.Series = append(output.Series, analyzeSeries(universalAqiCode, "index", "", points, input.SpikeMethod, input.SpikeThreshold, baseline, zone))
+		}
+		series, seriesUnits := pollutantSeries(snapshots)
+		for _, code := range sortedKeys(series) {
+			if len(input.Pollutants) > 0 && !slices.Contains(input.Pollutants, code) {
+				continue
+			}
+			output.Series = append(output.Series, analyzeSeries(code, "pollutant", seriesUnits[code], series[code], input.SpikeMethod, input.SpikeThreshold, baseline, zone))
+		}
+
+		// Render response in the requested format
+		return renderResult(output, outputFormat, zone), nil
+	}
+}
+
+// analyzeSeries estimates the trend of a time-ordered series and finds its
+// spike episodes. Days for the trend test are calendar days in loc.
+func analyzeSeries(code, kind, seriesUnits string, points []seriesPoint, method string, threshold float64, baseline time.Duration, loc *time.Location) SeriesTrend {
+	values := seriesValues(points)
+	result := SeriesTrend{
+		Code:     code,
+		Kind:     kind,
+		Units:    seriesUnits,
+		Stats:    summarize(values),
+		Trend:    estimateTrend(points, loc),
+		Episodes: []SpikeEpisode{},
+	}
+
+	// The Universal AQI falls as air gets worse, so its spikes are drops
+	sign := 1.0
+	if code == universalAqiCode {
+		sign = -1
+	}
+	if result.Trend != nil {
+		switch {
+		case result.Trend.Direction == "stable":
+			result.Trend.AirQuality = "stable"
+		case (result.Trend.Direction == "increasing") == (sign > 0):
+			result.Trend.AirQuality = "worsening"
+		default:
+			result.Trend.AirQuality = "improving"
+		}
+	}
+	scores := spikeScores(points, method, baseline, sign)
+	var current *SpikeEpisode
+	var last time.Time
+	for i, p := range points {
+		s := scores[i]
+		if math.IsNaN(s.score) || s.score < threshold {
+			current = nil
+			continue
+		}
+		result.SpikeHours++
+		if current == nil || p.At.Sub(last) > time.Hour {
+			result.Episodes = append(result.Episodes, SpikeEpisode{StartTime: p.At.Format(time.RFC3339), Baseline: roundTo(s.baseline, 2)})
+			current = &result.Episodes[len(result.Episodes)-1]
+		}
+		current.EndTime = p.At.Add(time.Hour).Format(time.RFC3339)
+		current.Hours++
+		if current.PeakTime == "" || s.score > current.PeakScore {
+			current.Peak, current.PeakTime, current.PeakScore = roundTo(p.Value, 2), p.At.Format(time.RFC3339), roundTo(s.score, 2)
+			current.Magnitude = roundTo(p.Value-current.Baseline, 2)
+		}
+		last = p.At
+	}
+	for i := range result.Episodes {
+		result.Episodes[i].Label = episodeLabel(code, &result.Episodes[i], threshold)
+	}
+	return result
+}
+
+// spikeScore is an hour's score against its baseline; NaN when the baseline
+// is too short or flat to score against
+type spikeScore struct {
+	score    float64
+	baseline float64
+}
+
+// spikeScores scores every point against the points in the baseline window
+// preceding it. sign flips the direction that counts as a spike.
+func spikeScores(points []seriesPoint, method string, baseline time.Duration, sign float64) []spikeScore {
+	scores := make([]spikeScore, len(points))
+	from := 0
+	for i, p := range points {
+		for from < i && points[from].At.Before(p.At.Add(-baseline)) {
+			from++
+		}
+		scores[i] = spikeScore{score: math.NaN()}
+		window := seriesValues(points[from:i])
+		if len(window) < minBaselineHours {
+			continue
+		}
+		switch method {
+		case SpikeMethodZScore:
+			center, spread := mean(window), stddev(window)
+			scores[i].baseline = center
+			if spread > 0 {
+				scores[i].score = sign * (p.Value - center) / spread
+			}
+		default:
+			center := median(window)
+			deviations := make([]float64, len(window))
+			for j, v := range window {
+				deviations[j] = math.Abs(v - center)
+			}
+			// 0.6745 scales the MAD to a standard deviation for normal data
+			mad := median(deviations)
+			scores[i].baseline = center
+			if mad > 0 {
+				scores[i].score = sign * 0.6745 * (p.Value - center) / mad
+			}
+		}
+	}
+	return scores
+}
+
+// episodeLabel describes an episode in words
+func episodeLabel(code string, e *SpikeEpisode, threshold float64) string {
+	severity := "moderate"
+	switch {
+	case e.PeakScore >= 3*threshold:
+		severity = "extreme"
+	case e.PeakScore >= 2*threshold:
+		severity = "severe"
+	}
+	kind := "spike"
+	if code == universalAqiCode {
+		kind = "drop"
+	}
+	return fmt.Sprintf("%s %s %s lasting %dh", severity, code, kind, e.Hours)
+}
+
+// estimateTrend fits linear and Theil–Sen slopes and runs a Mann–Kendall test
+// on the daily means in loc; nil for fewer than three points
+func estimateTrend(points []seriesPoint, loc *time.Location) *TrendEstimate {
+	n := len(points)
+	if n < 3 {
+		return nil
+	}
+	// Time in hours since the first point
+	xs := make([]float64, n)
+	ys := seriesValues(points)
+	for i, p := range points {
+		xs[i] = p.At.Sub(points[0].At).Hours()
+	}
+
+	// Ordinary least squares
+	mx, my := mean(xs), mean(ys)
+	var sxx, sxy, syy float64
+	for i := range xs {
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		sxy += (xs[i] - mx) * (ys[i] - my)
+		syy += (ys[i] - my) * (ys[i] - my)
+	}
+	linear := LinearTrend{}
+	if sxx > 0 {
+		linear.SlopePerDay = roundTo(sxy/sxx*24, 4)
+		if syy > 0 {
+			linear.RSquared = roundTo(sxy*sxy/(sxx*syy), 4)
+		}
+	}
+
+	// Theil–Sen: the median of pairwise slopes
+	slopes := make([]float64, 0, n*(n-1)/2)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if dx := xs[j] - xs[i]; dx > 0 {
+				slopes = append(slopes, (ys[j]-ys[i])/dx)
+			}
+		}
+	}
+	theilSen := median(slopes)
+
+	// Hourly values follow a daily cycle and are strongly autocorrelated, so
+	// Mann–Kendall on them finds trends in noise; daily means are close to
+	// independent
+	var daily []float64
+	for _, v := range averageSeries(points, Averaging24h, loc) {
+		daily = append(daily, v.Value)
+	}
+
+	estimate := &TrendEstimate{
+		Linear:      linear,
+		TheilSen:    roundTo(theilSen*24, 4),
+		MannKendall: mannKendall(daily),
+	}
+	span := xs[n-1] - xs[0]
+	estimate.Change = roundTo(theilSen*span, 2)
+	if my != 0 {
+		estimate.PercentChange = roundTo(theilSen*span/math.Abs(my)*100, 1)
+	}
+	switch {
+	case !estimate.MannKendall.Significant || math.Abs(theilSen*span) < stableChangeRatio*math.Abs(my):
+		estimate.Direction = "stable"
+	case theilSen > 0:
+		estimate.Direction = "increasing"
+	default:
+		estimate.Direction = "decreasing"
+	}
+	return estimate
+}
+
+// mannKendall computes the test statistic's normal approximation with the
+// variance corrected for tied values. It needs five values to reach
+// significance and reports none for fewer than three.
+func mannKendall(values []float64) MannKendall {
+	if len(values) < 3 {
+		return MannKendall{Days: len(values), PValue: 1}
+	}
+	s := 0
+	for i := range values {
+		for j := i + 1; j < len(values); j++ {
+			switch {
+			case values[j] > values[i]:
+				s++
+			case values[j] < values[i]:
+				s--
+			}
+		}
+	}
+
+	n := float64(len(values))
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	variance := n * (n - 1) * (2*n + 5)
+	for i := 0; i < len(sorted); {
+		j := i
+		for j < len(sorted) && sorted[j] == sorted[i] {
+			j++
+		}
+		if t := float64(j - i); t > 1 {
+			variance -= t * (t - 1) * (2*t + 5)
+		}
+		i = j
+	}
+	variance /= 18
+
+	var z float64
+	switch {
+	case variance <= 0:
+	case s > 0:
+		z = float64(s-1) / math.Sqrt(variance)
+	case s < 0:
+		z = float64(s+1) / math.Sqrt(variance)
+	}
+	p := math.Erfc(math.Abs(z) / math.Sqrt2)
+	return MannKendall{
+		Days:        len(values),
+		S:           s,
+		Tau:         roundTo(float64(s)/(n*(n-1)/2), 4),
+		Z:           roundTo(z, 3),
+		PValue:      roundTo(p, 4),
+		Significant: p < trendSignificance,
+	}
+}
@@ -93,12 +93,13 @@ type HistoryRequest struct {
 
 // AQI represents an Air Quality Index
 type AQI struct {
-	Code        string `json:"code,omitempty"`
-	DisplayName string `json:"displayName,omitempty"`
-	Aqi         int    `json:"aqi,omitempty"`
-	AqiDisplay  string `json:"aqiDisplay,omitempty"`
-	Color       *Color `json:"color,omitempty"`
-	Category    string `json:"category,omitempty"`
+	Code              string `json:"code,omitempty"`
+	DisplayName       string `json:"displayName,omitempty"`
+	Aqi               int    `json:"aqi,omitempty"`
+	AqiDisplay        string `json:"aqiDisplay,omitempty"`
+	Color             *Color `json:"color,omitempty"`
+	Category          string `json:"category,omitempty"`
+	DominantPollutant string `json:"dominantPollutant,omitempty"`
 }
 
 // Color represents an RGB color
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+// unitsDescription documents the units input shared by data tools
+const unitsDescription = "Convert pollutant concentrations to these units (ppb ppm ug/m3 mg/m3, default: as reported by the API)"
+
+// parseUnitsInput parses the optional units argument of a data tool. An empty
+// value keeps the units reported by the API.
+func parseUnitsInput(value string) (units.Unit, error) {
+	if value == "" {
+		return "", nil
+	}
+	return units.Parse(value)
+}
+
+// convertPollutants converts pollutant concentrations in place to the target
+// units. Pollutants that cannot be expressed in the target units, such as
+// particulates in ppb, keep the units reported by the API.
+func convertPollutants(pollutants []Pollutant, target units.Unit) {
+	if target == "" {
+		return
+	}
+	for i, p := range pollutants {
+		if p.Concentration == nil {
+			continue
+		}
+		value, err := units.Convert(p.Code, p.Concentration.Value, units.Unit(p.Concentration.Units), target)
+		if err != nil {
+			continue
+		}
+		pollutants[i].Concentration = &Concentration{Value: roundTo(value, 3), Units: string(target)}
+	}
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +14,35 @@ type Config struct {
 	Port          string
 	APIKey        string
 	MCPServerName string
+	// Air temperature and pressure used to convert between ppb and µg/m³
+	UnitsTemperatureC float64
+	UnitsPressureHPa  float64
+	// How often alerts are checked against current conditions, how long they
+	// live and how many one session may hold
+	AlertPollInterval  time.Duration
+	AlertTTL           time.Duration
+	AlertMaxPerSession int
+	// Locations polled in the background to serve current conditions from
+	WatchLocations  []WatchLocation
+	WatchJitter     time.Duration
+	WatchMaxBackoff time.Duration
+	// Where fetched forecasts are archived for verification, if anywhere, and
+	// for how long
+	ForecastStoreDir  string
+	ForecastRetention time.Duration
+	// How long exports stay downloadable, how many of how many megabytes in
+	// total are kept, and the public URL download links are made absolute with
+	ExportTTL        time.Duration
+	ExportMaxEntries int
+	ExportMaxMB      int
+	PublicBaseURL    string
+}
+
+// WatchLocation is a location polled on a cron schedule
+type WatchLocation struct {
+	Latitude  float64
+	Longitude float64
+	Schedule  string
 }
 
 func LoadConfig() *Config {
@@ -23,6 +55,25 @@ func LoadConfig() *Config {
 		Port:          getEnv("PORT", "8080"),
 		APIKey:        getEnv("API_KEY", ""),
 		MCPServerName: getEnv("MCP_SERVER_NAME", "google-air-quality-mcp"),
+
+		UnitsTemperatureC: getEnvFloat("UNITS_TEMPERATURE_C", 25, -273.15),
+		UnitsPressureHPa:  getEnvFloat("UNITS_PRESSURE_HPA", 1013.25, 0),
+
+		AlertPollInterval:  getEnvDuration("ALERT_POLL_INTERVAL", 5*time.Minute),
+		AlertTTL:           getEnvDuration("ALERT_TTL", 24*time.Hour),
+		AlertMaxPerSession: getEnvInt("ALERT_MAX_PER_SESSION", 10),
+
+		WatchLocations:  getEnvWatchLocations("WATCH_LOCATIONS", getEnv("WATCH_SCHEDULE", "*/15 * * * *")),
+		WatchJitter:     getEnvDuration("WATCH_JITTER", 30*time.Second),
+		WatchMaxBackoff: getEnvDuration("WATCH_MAX_BACKOFF", 30*time.Minute),
+
+		ForecastStoreDir:  getEnv("FORECAST_STORE_DIR", ""),
+		ForecastRetention: getEnvDuration("FORECAST_RETENTION", 35*24*time.Hour),
+
+		ExportTTL:        getEnvDuration("EXPORT_TTL", time.Hour),
+		ExportMaxEntries: getEnvInt("EXPORT_MAX_ENTRIES", 100),
+		ExportMaxMB:      getEnvInt("EXPORT_MAX_MB", 256),
+		PublicBaseURL:    getEnv("PUBLIC_BASE_URL", ""),
 	}
 }
 
@@ -32,3 +83,69 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvFloat rejects values at or below above, such as temperatures at or
+// below absolute zero
+func getEnvFloat(key string, fallback, above float64) float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f <= above {
+		log.Printf("Invalid %s %q, using %v", key, value, fallback)
+		return fallback
+	}
+	return f
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s %q, using %v", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %v", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getEnvWatchLocations parses "lat,long[@cron];lat,long[@cron]" entries,
+// using schedule for entries without their own cron expression
+func getEnvWatchLocations(key, schedule string) []WatchLocation {
+	var locations []WatchLocation
+	for _, entry := range strings.Split(getEnv(key, ""), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		coords, cron, found := strings.Cut(entry, "@")
+		if !found {
+			cron = schedule
+		}
+		lat, lon, ok := strings.Cut(coords, ",")
+		latitude, errLat := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		longitude, errLon := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+		if !ok || errLat != nil || errLon != nil {
+			log.Printf("Invalid %s entry %q, skipping", key, entry)
+			continue
+		}
+		locations = append(locations, WatchLocation{Latitude: latitude, Longitude: longitude, Schedule: strings.TrimSpace(cron)})
+	}
+	return locations
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format is a serialization format for exported rows
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatGeoJSON Format = "geojson"
+	FormatXLSX    Format = "xlsx"
+)
+
+// Formats lists the supported formats
+var Formats = []Format{FormatCSV, FormatNDJSON, FormatGeoJSON, FormatXLSX}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	f := Format(strings.ToLower(name))
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format %q, expected one of %v", name, Formats)
+}
+
+// MIMEType returns the media type of the format
+func (f Format) MIMEType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatGeoJSON:
+		return "application/geo+json"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Binary reports whether the format is not plain text
+func (f Format) Binary() bool {
+	return f == FormatXLSX
+}
+
+// Row is one hour of one pollutant at a location. Hours without pollutant
+// data still get a row so their index values are kept.
+type Row struct {
+	Time              string   `json:"time"`
+	LocalTime         string   `json:"localTime,omitempty"`
+	Latitude          float64  `json:"latitude"`
+	Longitude         float64  `json:"longitude"`
+	Source            string   `json:"source"`
+	Pollutant         string   `json:"pollutant,omitempty"`
+	Concentration     *float64 `json:"concentration,omitempty"`
+	Units             string   `json:"units,omitempty"`
+	Uaqi              *int     `json:"uaqi,omitempty"`
+	UaqiCategory      string   `json:"uaqiCategory,omitempty"`
+	LocalAqiCode      string   `json:"localAqiCode,omitempty"`
+	LocalAqi          *int     `json:"localAqi,omitempty"`
+	DominantPollutant string   `json:"dominantPollutant,omitempty"`
+}
+
+// Columns are the column names of tabular exports, in order
+var Columns = []string{
+	"time", "local_time", "latitude", "longitude", "source", "pollutant", "concentration", "units",
+	"uaqi", "uaqi_category", "local_aqi_code", "local_aqi", "dominant_pollutant",
+}
+
+// cells returns the row's values in column order; nil marks an empty cell
+func (r Row) cells() []interface{} {
+	cells := []interface{}{
+		r.Time, r.LocalTime, r.Latitude, r.Longitude, r.Source, r.Pollutant, nil, r.Units,
+		nil, r.UaqiCategory, r.LocalAqiCode, nil, r.DominantPollutant,
+	}
+	if r.Concentration != nil {
+		cells[6] = *r.Concentration
+	}
+	if r.Uaqi != nil {
+		cells[8] = float64(*r.Uaqi)
+	}
+	if r.LocalAqi != nil {
+		cells[11] = float64(*r.LocalAqi)
+	}
+	return cells
+}
+
+// Encode serializes rows in the given format
+func Encode(format Format, rows []Row) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return encodeCSV(rows)
+	case FormatNDJSON:
+		return encodeNDJSON(rows)
+	case FormatGeoJSON:
+		return encodeGeoJSON(rows)
+	case FormatXLSX:
+		return encodeXLSX(rows)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// encodeCSV writes a header row followed by one line per row
+func encodeCSV(rows []Row) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(Columns); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(Columns))
+	for _, r := range rows {
+		for i, c := range r.cells() {
+			record[i] = formatCell(c)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// encodeNDJSON writes one JSON object per line
+func encodeNDJSON(rows []Row) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeGeoJSON writes a FeatureCollection with one point feature per row
+func encodeGeoJSON(rows []Row) ([]byte, error) {
+	type geometry struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string   `json:"type"`
+		Geometry   geometry `json:"geometry"`
+		Properties Row      `json:"properties"`
+	}
+	collection := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: make([]feature, 0, len(rows))}
+	for _, r := range rows {
+		collection.Features = append(collection.Features, feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "Point", Coordinates: []float64{r.Longitude, r.Latitude}},
+			Properties: r,
+		})
+	}
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+// formatCell renders a cell value as text
+func formatCell(c interface{}) string {
+	switch v := c.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export is a serialized result kept for download until it expires
+type Export struct {
+	ID        string
+	Filename  string
+	Format    Format
+	Data      []byte
+	ExpiresAt time.Time
+}
+
+// Store keeps exports in memory until they expire, within a limit on their
+// number and total size
+type Store struct {
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int
+	mu         sync.Mutex
+	exports    map[string]*Export
+	size       int
+}
+
+// NewStore creates a store whose exports expire after ttl and that holds at
+// most maxEntries exports of maxBytes in total
+func NewStore(ttl time.Duration, maxEntries, maxBytes int) *Store {
+	return &Store{ttl: ttl, maxEntries: maxEntries, maxBytes: maxBytes, exports: map[string]*Export{}}
+}
+
+// SetLimits changes how long new exports are kept and how many exports, of
+// how many bytes in total, the store holds
+func (s *Store) SetLimits(ttl time.Duration, maxEntries, maxBytes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl, s.maxEntries, s.maxBytes = ttl, maxEntries, maxBytes
+}
+
+// Put stores data under a new random ID. Expired exports are dropped, and
+// the oldest exports are evicted while the store is over its limits.
+func (s *Store) Put(filename string, format Format, data []byte) (*Export, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(data) > s.maxBytes {
+		return nil, fmt.Errorf("export of %d bytes is larger than the %d byte limit", len(data), s.maxBytes)
+	}
+	now := time.Now()
+	s.removeExpired(now)
+	for len(s.exports) > 0 && (len(s.exports) >= s.maxEntries || s.size+len(data) > s.maxBytes) {
+		s.remove(s.oldest())
+	}
+	e := &Export{
+		ID:        hex.EncodeToString(id),
+		Filename:  filename,
+		Format:    format,
+		Data:      data,
+		ExpiresAt: now.Add(s.ttl).UTC(),
+	}
+	s.exports[e.ID] = e
+	s.size += len(data)
+	return e, nil
+}
+
+// Get returns an export that has not expired and drops expired exports
+func (s *Store) Get(id string) (*Export, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired(time.Now())
+	e, ok := s.exports[id]
+	return e, ok
+}
+
+// removeExpired drops exports past their expiry. The caller must hold s.mu.
+func (s *Store) removeExpired(now time.Time) {
+	for id, e := range s.exports {
+		if now.After(e.ExpiresAt) {
+			s.remove(id)
+		}
+	}
+}
+
+// oldest returns the ID of the export that expires first, which is the
+// oldest while the TTL is unchanged. The caller must hold s.mu.
+func (s *Store) oldest() string {
+	var oldest *Export
+	for _, e := range s.exports {
+		if oldest == nil || e.ExpiresAt.Before(oldest.ExpiresAt) {
+			oldest = e
+		}
+	}
+	return oldest.ID
+}
+
+// remove drops an export. The caller must hold s.mu.
+func (s *Store) remove(id string) {
+	if e, ok := s.exports[id]; ok {
+		s.size -= len(e.Data)
+		delete(s.exports, id)
+	}
+}
+
+// Handler serves exports as downloads at a route with an :id parameter
+func (s *Store) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		e, ok := s.Get(c.Param("id"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "export not found or expired"})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="`+e.Filename+`"`)
+		c.Header("Expires", e.ExpiresAt.Format(http.TimeFormat))
+		c.Data(http.StatusOK, e.Format.MIMEType(), e.Data)
+	}
+}
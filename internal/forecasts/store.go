@@ -0,0 +1,164 @@
+package forecasts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Hour is one forecast hour with values per pollutant or index code
+type Hour struct {
+	Time       time.Time          `json:"time"`
+	Values     map[string]float64 `json:"values"`
+	Units      map[string]string  `json:"units,omitempty"`
+	Categories map[string]string  `json:"categories,omitempty"`
+}
+
+// Record is a forecast as it was fetched at IssuedAt
+type Record struct {
+	IssuedAt  time.Time `json:"issuedAt"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Hours     []Hour    `json:"hours"`
+}
+
+// compactInterval is how often a location's file is rewritten without its
+// expired records
+const compactInterval = 24 * time.Hour
+
+// Store keeps forecast records in one NDJSON file per location
+type Store struct {
+	dir       string
+	retention time.Duration
+	mu        sync.Mutex
+	compacted map[string]time.Time
+}
+
+// NewStore creates a store in dir that drops records older than retention
+func NewStore(dir string, retention time.Duration) *Store {
+	return &Store{dir: dir, retention: retention, compacted: map[string]time.Time{}}
+}
+
+// Key identifies a location independently of how its coordinates are written.
+// Forecasts for points within about 10 m share a file.
+func Key(lat, lon float64) string {
+	return fmt.Sprintf("%.4f_%.4f", lat, lon)
+}
+
+// path returns the file holding a location's records
+func (s *Store) path(lat, lon float64) string {
+	return filepath.Join(s.dir, Key(lat, lon)+".ndjson")
+}
+
+// Append adds a record to the end of its location's file. Expired records
+// are dropped when the file is compacted, at most once per compactInterval.
+func (s *Store) Append(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	path := s.path(record.Latitude, record.Longitude)
+	if time.Since(s.compacted[path]) >= compactInterval {
+		if err := s.compact(record.Latitude, record.Longitude); err != nil {
+			return err
+		}
+		s.compacted[path] = time.Now()
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// compact rewrites a location's file without its expired records
+func (s *Store) compact(lat, lon float64) error {
+	records, err := s.read(lat, lon)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	cutoff := time.Now().Add(-s.retention)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		if r.IssuedAt.Before(cutoff) {
+			continue
+		}
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	// Write to a temporary file and rename so readers never see a partial file
+	path := s.path(lat, lon)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load returns a location's unexpired records issued at or after since,
+// oldest first
+func (s *Store) Load(lat, lon float64, since time.Time) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.read(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	if cutoff := time.Now().Add(-s.retention); since.Before(cutoff) {
+		since = cutoff
+	}
+	var out []Record
+	for _, r := range records {
+		if !r.IssuedAt.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// read parses a location's file; a missing file holds no records
+func (s *Store) read(lat, lon float64) ([]Record, error) {
+	f, err := os.Open(s.path(lat, lon))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// Skip lines damaged by an interrupted write
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
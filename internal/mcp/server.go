@@ -1,10 +1,12 @@
 package mcp
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
 	"github.com/gin-gonic/gin"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -13,12 +15,14 @@ type MCPServer struct {
 	server *mcp.Server
 }
 
-func NewMCPServer(name string, version string) *MCPServer {
+// NewMCPServer creates the server and registers its features. Background
+// work such as polling runs until ctx is done.
+func NewMCPServer(ctx context.Context, name string, version string) *MCPServer {
 	// Create a server
-	s := mcp.NewServer(&mcp.Implementation{Name: name, Version: version}, nil)
+	s := mcp.NewServer(&mcp.Implementation{Name: name, Version: version}, capabilities.ServerOptions())
 
 	// Register all features (tools, prompts, resources)
-	capabilities.RegisterAll(s)
+	capabilities.RegisterAll(ctx, s)
 
 	return &MCPServer{
 		server: s,
@@ -46,3 +50,10 @@ func (s *MCPServer) SetupStreamableHTTP(r *gin.Engine) {
 
 	log.Println("Streamable HTTP endpoint registered at /mcp")
 }
+
+// SetupExports serves files created by the export tool until they expire
+func (s *MCPServer) SetupExports(r *gin.Engine) {
+	r.GET("/exports/:id", tools.Exports.Handler())
+
+	log.Println("Export downloads registered at /exports/:id")
+}
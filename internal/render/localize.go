@@ -0,0 +1,73 @@
+package render
+
+import (
+	"fmt"
+	"time"
+)
+
+// Localize returns the JSON form of v with a <field>Local copy, in loc and
+// with its UTC offset, next to every RFC 3339 timestamp field, and the zone
+// name as timeZone on the top-level object. The result can be passed to
+// Render or marshalled like v.
+func Localize(v interface{}, loc *time.Location) (interface{}, error) {
+	tree, err := toTree(v)
+	if err != nil || loc == nil {
+		return tree, err
+	}
+	if obj, ok := tree.(*object); ok {
+		obj.set("timeZone", loc.String())
+	}
+	localize(tree, loc)
+	return tree, nil
+}
+
+// Prepend returns the JSON form of v with key set to value as the first field
+// of the top-level object. The result can be passed to Localize or Render.
+func Prepend(v interface{}, key string, value interface{}) (interface{}, error) {
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := tree.(*object)
+	if !ok {
+		return nil, fmt.Errorf("cannot add %s to a non-object value", key)
+	}
+	node, err := toTree(value)
+	if err != nil {
+		return nil, err
+	}
+	if !obj.has(key) {
+		obj.keys = append([]string{key}, obj.keys...)
+	}
+	obj.values[key] = node
+	return obj, nil
+}
+
+// localize annotates the timestamps of a node in place
+func localize(node interface{}, loc *time.Location) {
+	switch v := node.(type) {
+	case *object:
+		// Each local copy goes right after its timestamp
+		keys := make([]string, 0, len(v.keys))
+		for _, key := range v.keys {
+			keys = append(keys, key)
+			child := v.values[key]
+			s, ok := child.(string)
+			if !ok {
+				localize(child, loc)
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil || v.has(key+"Local") {
+				continue
+			}
+			keys = append(keys, key+"Local")
+			v.values[key+"Local"] = t.In(loc).Format(time.RFC3339)
+		}
+		v.keys = keys
+	case []interface{}:
+		for _, child := range v {
+			localize(child, loc)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdown renders a tree as bullet lists for fields and tables for lists
+func markdown(tree interface{}) string {
+	var sb strings.Builder
+	writeMarkdown(&sb, tree, 2)
+	return strings.TrimSpace(sb.String())
+}
+
+// writeMarkdown writes a node with nested sections at the given heading level
+func writeMarkdown(sb *strings.Builder, node interface{}, level int) {
+	switch v := node.(type) {
+	case *object:
+		// Fields that fit on one line come first, sections after them
+		var sections []string
+		for _, key := range v.keys {
+			if text, ok := inline(v.values[key]); ok {
+				fmt.Fprintf(sb, "- **%s**: %s\n", key, text)
+			} else {
+				sections = append(sections, key)
+			}
+		}
+		for _, key := range sections {
+			fmt.Fprintf(sb, "\n%s %s\n\n", heading(level), key)
+			writeMarkdown(sb, v.values[key], level+1)
+		}
+	case []interface{}:
+		if nested(v) {
+			for i, item := range v {
+				fmt.Fprintf(sb, "\n%s %s\n\n", heading(level), itemTitle(item, i))
+				writeMarkdown(sb, item, level+1)
+			}
+			return
+		}
+		writeTable(sb, newTable(v))
+	default:
+		sb.WriteString(formatScalar(v) + "\n")
+	}
+}
+
+// inline returns the one-line text of scalars, measurements and scalar lists
+func inline(node interface{}) (string, bool) {
+	switch v := node.(type) {
+	case *object:
+		if c, ok := measurement(v); ok {
+			return withUnit(c.text, c.unit), true
+		}
+		return "", false
+	case []interface{}:
+		texts, ok := scalarList(v)
+		return strings.Join(texts, ", "), ok
+	default:
+		return escape(formatScalar(v)), true
+	}
+}
+
+// nested reports whether list items hold lists of their own that a table
+// row cannot show, so each item needs its own section
+func nested(list []interface{}) bool {
+	for _, item := range list {
+		obj, ok := item.(*object)
+		if !ok {
+			return false
+		}
+		for _, key := range obj.keys {
+			child, ok := obj.values[key].([]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := scalarList(child); !ok && !coded(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// itemTitle names a list item by its code or name, or its position
+func itemTitle(item interface{}, i int) string {
+	if obj, ok := item.(*object); ok {
+		for _, key := range []string{"code", "name", "pollutant", "id"} {
+			if s, ok := obj.get(key).(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// writeTable writes a markdown table; units shared by a column move to its
+// header
+func writeTable(sb *strings.Builder, t *table) {
+	if len(t.columns) == 0 {
+		return
+	}
+	units := make([]string, len(t.columns))
+	headers := make([]string, len(t.columns))
+	for i, name := range t.columns {
+		units[i] = t.columnUnit(name)
+		headers[i] = escape(name)
+		if units[i] != "" {
+			headers[i] += " (" + units[i] + ")"
+		}
+	}
+	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat("---|", len(t.columns)) + "\n")
+	for _, row := range t.rows {
+		cells := make([]string, len(t.columns))
+		for i, name := range t.columns {
+			c := row[name]
+			text := c.text
+			if units[i] == "" {
+				text = withUnit(text, c.unit)
+			}
+			cells[i] = escape(text)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+// heading returns the markdown heading marker for a level
+func heading(level int) string {
+	return strings.Repeat("#", min(level, 6))
+}
+
+// withUnit appends a unit to a text when there is one
+func withUnit(text, unit string) string {
+	if unit == "" || text == "" {
+		return text
+	}
+	return text + " " + unit
+}
+
+// escape keeps a value on one line and out of the table syntax
+func escape(text string) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
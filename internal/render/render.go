@@ -0,0 +1,102 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Format is the representation a tool response is rendered in
+type Format string
+
+const (
+	FormatJSON        Format = "json"
+	FormatCompactJSON Format = "compact-json"
+	FormatMarkdown    Format = "markdown"
+	FormatSummary     Format = "summary"
+)
+
+// Formats lists the supported formats
+var Formats = []Format{FormatJSON, FormatCompactJSON, FormatMarkdown, FormatSummary}
+
+// ParseFormat validates a format name. An empty name selects indented JSON.
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return FormatJSON, nil
+	}
+	f := Format(strings.ToLower(name))
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid outputFormat %q, expected one of %v", name, Formats)
+}
+
+// verboseKeys are presentation fields repeated on every index and pollutant
+// that compact formats leave out
+var verboseKeys = map[string]bool{
+	"color":          true,
+	"displayName":    true,
+	"fullName":       true,
+	"additionalInfo": true,
+}
+
+// Render renders v in the given format
+func Render(format Format, v interface{}) (string, error) {
+	if format == FormatJSON {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	}
+
+	tree, err := toTree(v)
+	if err != nil {
+		return "", err
+	}
+	tree = compact(tree)
+	switch format {
+	case FormatCompactJSON:
+		data, err := json.Marshal(tree)
+		return string(data), err
+	case FormatMarkdown:
+		return markdown(tree), nil
+	case FormatSummary:
+		return summary(tree), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// scalar reports whether a node is a string, number or bool
+func scalar(node interface{}) bool {
+	switch node.(type) {
+	case *object, []interface{}:
+		return false
+	default:
+		return true
+	}
+}
+
+// formatScalar renders a scalar; numbers keep their shortest form
+func formatScalar(node interface{}) string {
+	switch v := node.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return formatNumber(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatNumber renders a number with at most three decimals
+func formatNumber(v float64) string {
+	s := fmt.Sprintf("%.3f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxSummaryText is the longest text value quoted in a summary
+	maxSummaryText = 160
+	// maxSummaryItems is the most list items or columns named in a summary
+	maxSummaryItems = 8
+)
+
+// summary renders a tree as one paragraph: top-level fields, the items of
+// short coded lists and the span and value ranges of longer lists
+func summary(tree interface{}) string {
+	parts := summaryParts(tree, "", 0)
+	if len(parts) == 0 {
+		return "No data."
+	}
+	return strings.Join(parts, "; ") + "."
+}
+
+// summaryParts returns the summary phrases of a node
+func summaryParts(node interface{}, name string, depth int) []string {
+	label := func(text string) string {
+		if name == "" {
+			return text
+		}
+		return name + ": " + text
+	}
+
+	switch v := node.(type) {
+	case *object:
+		if c, ok := measurement(v); ok {
+			return []string{label(withUnit(c.text, c.unit))}
+		}
+		if depth > 1 {
+			return nil
+		}
+		var parts []string
+		for _, key := range v.keys {
+			parts = append(parts, summaryParts(v.values[key], join(name, key), depth+1)...)
+		}
+		return parts
+	case []interface{}:
+		if texts, ok := scalarList(v); ok {
+			return []string{label(strings.Join(truncateList(texts), ", "))}
+		}
+		if coded(v) && len(v) <= maxSummaryItems {
+			var items []string
+			for _, item := range v {
+				obj := item.(*object)
+				if c, ok := primary(obj); ok {
+					items = append(items, formatScalar(obj.get("code"))+" "+withUnit(c.text, c.unit))
+				}
+			}
+			if len(items) > 0 {
+				return []string{label(strings.Join(items, ", "))}
+			}
+		}
+		return []string{label(listSummary(v))}
+	default:
+		text := formatScalar(v)
+		if runes := []rune(text); len(runes) > maxSummaryText {
+			text = string(runes[:maxSummaryText]) + "…"
+		}
+		return []string{label(text)}
+	}
+}
+
+// listSummary describes a list of objects by its length, the span of its
+// first time column and the ranges of its numeric columns
+func listSummary(list []interface{}) string {
+	t := newTable(list)
+	text := fmt.Sprintf("%d entries", len(list))
+	var ranges []string
+	for _, name := range t.columns {
+		if timeColumn(name) && !strings.Contains(text, " from ") {
+			first, last := t.rows[0][name].text, t.rows[len(t.rows)-1][name].text
+			if first != "" && last != "" {
+				text += fmt.Sprintf(" from %s to %s", first, last)
+			}
+			continue
+		}
+		if len(ranges) < maxSummaryItems {
+			if r, ok := columnRange(t, name); ok {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	if len(ranges) > 0 {
+		text += " (" + strings.Join(ranges, ", ") + ")"
+	}
+	return text
+}
+
+// columnRange returns the min–max of a numeric column
+func columnRange(t *table, name string) (string, bool) {
+	var lo, hi float64
+	count := 0
+	for _, row := range t.rows {
+		c, ok := row[name]
+		if !ok || c.number == nil {
+			continue
+		}
+		if count == 0 || *c.number < lo {
+			lo = *c.number
+		}
+		if count == 0 || *c.number > hi {
+			hi = *c.number
+		}
+		count++
+	}
+	if count == 0 {
+		return "", false
+	}
+	text := formatNumber(lo)
+	if hi != lo {
+		text += "–" + formatNumber(hi)
+	}
+	return name + " " + withUnit(text, t.columnUnit(name)), true
+}
+
+// timeColumn reports whether a column holds timestamps
+func timeColumn(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "time") || strings.Contains(lower, "date")
+}
+
+// truncateList keeps the first maxSummaryItems texts
+func truncateList(texts []string) []string {
+	if len(texts) <= maxSummaryItems {
+		return texts
+	}
+	return append(texts[:maxSummaryItems:maxSummaryItems], "…")
+}
@@ -0,0 +1,173 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/units"
+)
+
+// cell is one table value; number is set for numeric values
+type cell struct {
+	text   string
+	number *float64
+	unit   string
+}
+
+// table is a list of objects flattened to rows of named cells
+type table struct {
+	columns []string
+	rows    []map[string]cell
+}
+
+// newTable flattens each element of a list to a row. Nested objects become
+// dotted columns and lists of coded items, such as indexes and pollutants,
+// become one column per code.
+func newTable(list []interface{}) *table {
+	t := &table{}
+	seen := map[string]bool{}
+	for _, item := range list {
+		row := map[string]cell{}
+		var order []string
+		flatten(row, &order, "", item)
+		for _, name := range order {
+			if !seen[name] {
+				seen[name] = true
+				t.columns = append(t.columns, name)
+			}
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t
+}
+
+// columnUnit returns the unit shared by every value of a column, or ""
+func (t *table) columnUnit(name string) string {
+	unit := ""
+	for _, row := range t.rows {
+		c, ok := row[name]
+		if !ok {
+			continue
+		}
+		if c.unit == "" || (unit != "" && c.unit != unit) {
+			return ""
+		}
+		unit = c.unit
+	}
+	return unit
+}
+
+// flatten adds the cells of a node to a row under the given column prefix
+func flatten(row map[string]cell, order *[]string, prefix string, node interface{}) {
+	add := func(name string, c cell) {
+		if _, ok := row[name]; !ok {
+			*order = append(*order, name)
+		}
+		row[name] = c
+	}
+
+	switch v := node.(type) {
+	case *object:
+		if c, ok := measurement(v); ok {
+			add(prefix, c)
+			return
+		}
+		for _, key := range v.keys {
+			flatten(row, order, join(prefix, key), v.values[key])
+		}
+	case []interface{}:
+		if texts, ok := scalarList(v); ok {
+			add(prefix, cell{text: strings.Join(texts, ", ")})
+			return
+		}
+		if coded(v) {
+			for _, item := range v {
+				obj := item.(*object)
+				if c, ok := primary(obj); ok {
+					add(formatScalar(obj.get("code")), c)
+				}
+			}
+			return
+		}
+		add(prefix, cell{text: formatScalar(float64(len(v))) + " items"})
+	default:
+		c := cell{text: formatScalar(v)}
+		if n, ok := v.(float64); ok {
+			c.number = &n
+		}
+		add(prefix, c)
+	}
+}
+
+// measurement reads a {value, units} object such as a concentration
+func measurement(obj *object) (cell, bool) {
+	value, ok := obj.get("value").(float64)
+	if !ok {
+		return cell{}, false
+	}
+	for _, key := range obj.keys {
+		if key != "value" && key != "units" {
+			return cell{}, false
+		}
+	}
+	unit, _ := obj.get("units").(string)
+	if unit != "" {
+		unit = units.Unit(unit).Symbol()
+	}
+	return cell{text: formatNumber(value), number: &value, unit: unit}, true
+}
+
+// coded reports whether a list holds only objects identified by a code
+func coded(list []interface{}) bool {
+	for _, item := range list {
+		obj, ok := item.(*object)
+		if !ok {
+			return false
+		}
+		if _, ok := obj.get("code").(string); !ok {
+			return false
+		}
+	}
+	return len(list) > 0
+}
+
+// primary returns the main value of a coded item: an index value with its
+// category, a concentration or a plain value
+func primary(obj *object) (cell, bool) {
+	if aqi, ok := obj.get("aqi").(float64); ok {
+		c := cell{text: formatNumber(aqi), number: &aqi}
+		if category, ok := obj.get("category").(string); ok && category != "" {
+			c.text += " (" + category + ")"
+		}
+		return c, true
+	}
+	if conc, ok := obj.get("concentration").(*object); ok {
+		return measurement(conc)
+	}
+	if c, ok := measurement(obj); ok {
+		return c, true
+	}
+	if value, ok := obj.get("value").(float64); ok {
+		return cell{text: formatNumber(value), number: &value}, true
+	}
+	return cell{}, false
+}
+
+// scalarList returns the texts of a list of scalars
+func scalarList(list []interface{}) ([]string, bool) {
+	texts := make([]string, 0, len(list))
+	for _, item := range list {
+		if !scalar(item) {
+			return nil, false
+		}
+		texts = append(texts, formatScalar(item))
+	}
+	return texts, true
+}
+
+// join joins column path segments with a dot
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,156 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// object is a JSON object that keeps its keys in document order, so rendered
+// tables and summaries follow the field order of the response types
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// get returns the value of a key, or nil
+func (o *object) get(key string) interface{} {
+	return o.values[key]
+}
+
+// has reports whether a key is present
+func (o *object) has(key string) bool {
+	_, ok := o.values[key]
+	return ok
+}
+
+// set adds or replaces a key, keeping its first position
+func (o *object) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON writes the object with its keys in order
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toTree converts v to the objects, slices and scalars of its JSON form
+func toTree(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeNode(dec)
+}
+
+// decodeNode reads one value from the decoder
+func decodeNode(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			list := []interface{}{}
+			for dec.More() {
+				child, err := decodeNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, child)
+			}
+			_, err := dec.Token()
+			return list, err
+		}
+		obj := &object{values: map[string]interface{}{}}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", keyTok)
+			}
+			child, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key, child)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Number:
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
+
+// compact drops verbose keys, nulls and empty values from a tree
+func compact(node interface{}) interface{} {
+	switch v := node.(type) {
+	case *object:
+		out := &object{values: map[string]interface{}{}}
+		for _, key := range v.keys {
+			if verboseKeys[key] {
+				continue
+			}
+			if c := compact(v.values[key]); !empty(c) {
+				out.set(key, c)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, child := range v {
+			if c := compact(child); !empty(c) {
+				out = append(out, c)
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// empty reports whether a compacted value carries no information
+func empty(node interface{}) bool {
+	switch v := node.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case *object:
+		return len(v.keys) == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
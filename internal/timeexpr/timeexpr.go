@@ -0,0 +1,199 @@
+// Package timeexpr resolves relative and natural-language time expressions
+// such as "now-6h", "yesterday", "last 3 days" or "tomorrow morning" against
+// a reference time in a given time zone.
+package timeexpr
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Range is the interval an expression covers. Expressions naming a single
+// moment, such as "now-6h", have Start equal to End.
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Instant reports whether the range is a single moment
+func (r Range) Instant() bool {
+	return r.Start.Equal(r.End)
+}
+
+// dayPart is a named part of a day as hours after midnight. Night runs past
+// midnight into the next day.
+type dayPart struct {
+	start, end int
+}
+
+var dayParts = map[string]dayPart{
+	"morning":   {6, 12},
+	"afternoon": {12, 18},
+	"evening":   {18, 22},
+	"night":     {22, 30},
+}
+
+// dayOffsets are the named days relative to today
+var dayOffsets = map[string]int{
+	"today":     0,
+	"this":      0,
+	"yesterday": -1,
+	"tomorrow":  1,
+}
+
+const unitPattern = `(m|mins?|minutes?|h|hrs?|hours?|d|days?|w|wks?|weeks?)`
+
+var (
+	nowOffsetRe = regexp.MustCompile(`^now\s*([+-])\s*(\d+)\s*` + unitPattern + `$`)
+	agoRe       = regexp.MustCompile(`^(\d+|an?)\s*` + unitPattern + `\s+ago$`)
+	inRe        = regexp.MustCompile(`^in\s+(\d+|an?)\s*` + unitPattern + `$`)
+	spanRe      = regexp.MustCompile(`^(last|past|next)\s+(?:(\d+)\s*)?` + unitPattern + `$`)
+	dayRe       = regexp.MustCompile(`^(today|yesterday|tomorrow|this)(?:\s+(morning|afternoon|evening|night))?$`)
+	clockRe     = regexp.MustCompile(`^(today|yesterday|tomorrow)\s+(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// Parse resolves an expression against now, reading calendar days in loc.
+// It reports false when the expression is not recognized. Supported forms:
+//
+//   - now, now-6h, now+2d, 3 hours ago, in 2 days
+//   - last 3 days, past 12 hours, next 6 hours, last week
+//   - today, yesterday, tomorrow
+//   - morning, afternoon, evening and night, alone or after a day, e.g.
+//     tomorrow morning, this evening, tonight and last night
+//   - a day with a clock time, e.g. tomorrow 9am or yesterday at 18:30
+func Parse(expr string, now time.Time, loc *time.Location) (Range, bool) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	expr = strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+
+	switch expr {
+	case "now":
+		return Range{Start: now, End: now}, true
+	case "tonight":
+		return dayPartRange(now, 0, dayPart{18, 30}), true
+	case "last night":
+		return dayPartRange(now, -1, dayParts["night"]), true
+	}
+	if part, ok := dayParts[expr]; ok {
+		return dayPartRange(now, 0, part), true
+	}
+
+	if m := nowOffsetRe.FindStringSubmatch(expr); m != nil {
+		d, ok := duration(m[2], m[3])
+		if !ok {
+			return Range{}, false
+		}
+		if m[1] == "-" {
+			d = -d
+		}
+		t := now.Add(d)
+		return Range{Start: t, End: t}, true
+	}
+	if m := agoRe.FindStringSubmatch(expr); m != nil {
+		d, ok := duration(m[1], m[2])
+		if !ok {
+			return Range{}, false
+		}
+		t := now.Add(-d)
+		return Range{Start: t, End: t}, true
+	}
+	if m := inRe.FindStringSubmatch(expr); m != nil {
+		d, ok := duration(m[1], m[2])
+		if !ok {
+			return Range{}, false
+		}
+		t := now.Add(d)
+		return Range{Start: t, End: t}, true
+	}
+	if m := spanRe.FindStringSubmatch(expr); m != nil {
+		d, ok := duration(m[2], m[3])
+		if !ok {
+			return Range{}, false
+		}
+		if m[1] == "next" {
+			return Range{Start: now, End: now.Add(d)}, true
+		}
+		return Range{Start: now.Add(-d), End: now}, true
+	}
+	if m := dayRe.FindStringSubmatch(expr); m != nil {
+		offset := dayOffsets[m[1]]
+		if m[2] == "" {
+			if m[1] == "this" {
+				return Range{}, false
+			}
+			return dayPartRange(now, offset, dayPart{0, 24}), true
+		}
+		return dayPartRange(now, offset, dayParts[m[2]]), true
+	}
+	if m := clockRe.FindStringSubmatch(expr); m != nil {
+		hour, _ := strconv.Atoi(m[2])
+		minute := 0
+		if m[3] != "" {
+			minute, _ = strconv.Atoi(m[3])
+		}
+		switch m[4] {
+		case "am", "pm":
+			if hour < 1 || hour > 12 {
+				return Range{}, false
+			}
+			hour %= 12
+			if m[4] == "pm" {
+				hour += 12
+			}
+		}
+		if hour > 23 || minute > 59 {
+			return Range{}, false
+		}
+		y, mo, d := now.Date()
+		t := time.Date(y, mo, d+dayOffsets[m[1]], hour, minute, 0, 0, loc)
+		return Range{Start: t, End: t}, true
+	}
+	return Range{}, false
+}
+
+// dayPartRange returns a part of the day offset days from now's date.
+// Calendar arithmetic keeps days whole across daylight saving changes.
+func dayPartRange(now time.Time, offset int, part dayPart) Range {
+	y, m, d := now.Date()
+	loc := now.Location()
+	return Range{
+		Start: time.Date(y, m, d+offset, part.start, 0, 0, 0, loc),
+		End:   time.Date(y, m, d+offset, part.end, 0, 0, 0, loc),
+	}
+}
+
+// duration converts an amount and unit to a duration. A missing amount, or
+// "a" and "an", count as one. It reports false for an amount below one or
+// one too large for a time.Duration.
+func duration(amount, unit string) (time.Duration, bool) {
+	n := 1
+	switch amount {
+	case "", "a", "an":
+	default:
+		v, err := strconv.Atoi(amount)
+		if err != nil || v < 1 {
+			return 0, false
+		}
+		n = v
+	}
+	var per time.Duration
+	switch unit[0] {
+	case 'm':
+		per = time.Minute
+	case 'h':
+		per = time.Hour
+	case 'd':
+		per = 24 * time.Hour
+	case 'w':
+		per = 7 * 24 * time.Hour
+	}
+	if int64(n) > math.MaxInt64/int64(per) {
+		return 0, false
+	}
+	return time.Duration(n) * per, true
+}
@@ -0,0 +1,88 @@
+package timeexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Daylight saving time starts in New York the next day, 2024-03-10
+	now := time.Date(2024, 3, 9, 14, 30, 0, 0, loc)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, loc)
+	}
+	instant := func(t time.Time) Range { return Range{Start: t, End: t} }
+
+	tests := []struct {
+		expr string
+		want Range
+	}{
+		{"now", instant(now)},
+		{"  NOW  ", instant(now)},
+		{"now-6h", instant(now.Add(-6 * time.Hour))},
+		{"now + 2d", instant(now.Add(48 * time.Hour))},
+		{"now-90 minutes", instant(now.Add(-90 * time.Minute))},
+		{"3 hours ago", instant(now.Add(-3 * time.Hour))},
+		{"an hour ago", instant(now.Add(-time.Hour))},
+		{"in 2 days", instant(now.Add(48 * time.Hour))},
+		{"in a week", instant(now.Add(7 * 24 * time.Hour))},
+		{"last 3 days", Range{Start: now.Add(-72 * time.Hour), End: now}},
+		{"past 12 hours", Range{Start: now.Add(-12 * time.Hour), End: now}},
+		{"next 6 hours", Range{Start: now, End: now.Add(6 * time.Hour)}},
+		{"last week", Range{Start: now.Add(-7 * 24 * time.Hour), End: now}},
+		{"today", Range{Start: at(9, 0, 0), End: at(10, 0, 0)}},
+		{"yesterday", Range{Start: at(8, 0, 0), End: at(9, 0, 0)}},
+		// The day of the change is 23 hours long
+		{"tomorrow", Range{Start: at(10, 0, 0), End: at(11, 0, 0)}},
+		{"morning", Range{Start: at(9, 6, 0), End: at(9, 12, 0)}},
+		{"this evening", Range{Start: at(9, 18, 0), End: at(9, 22, 0)}},
+		{"tomorrow morning", Range{Start: at(10, 6, 0), End: at(10, 12, 0)}},
+		{"yesterday afternoon", Range{Start: at(8, 12, 0), End: at(8, 18, 0)}},
+		{"tonight", Range{Start: at(9, 18, 0), End: at(10, 6, 0)}},
+		{"last night", Range{Start: at(8, 22, 0), End: at(9, 6, 0)}},
+		{"tomorrow 9am", instant(at(10, 9, 0))},
+		{"tomorrow 12am", instant(at(10, 0, 0))},
+		{"today 12pm", instant(at(9, 12, 0))},
+		{"yesterday at 18:30", instant(at(8, 18, 30))},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.expr, now, loc)
+		if !ok {
+			t.Errorf("Parse(%q) not recognized", tt.expr)
+			continue
+		}
+		if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+			t.Errorf("Parse(%q) = %v to %v, want %v to %v", tt.expr, got.Start, got.End, tt.want.Start, tt.want.End)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC)
+	for _, expr := range []string{
+		"",
+		"later",
+		"this",
+		"now-0h",
+		"0 days ago",
+		"last 0 hours",
+		// Too large for an int
+		"now-99999999999999999999h",
+		"in 99999999999999999999 days",
+		// Too large for a time.Duration
+		"last 9999999999 weeks",
+		"now+3000000h",
+		"tomorrow 13pm",
+		"today 0am",
+		"yesterday 24:00",
+		"today 10:60",
+	} {
+		if r, ok := Parse(expr, now, time.UTC); ok {
+			t.Errorf("Parse(%q) = %v to %v, want not recognized", expr, r.Start, r.End)
+		}
+	}
+}
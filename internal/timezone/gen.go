@@ -0,0 +1,237 @@
+//go:build ignore
+
+// gen converts the reduced timezone-boundary-builder polygons published by
+// tzf-rel-lite (combined-with-oceans.reduce.bin) to zones.bin.gz.
+//
+// Usage: go run gen.go path/to/combined-with-oceans.reduce.bin
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// scale is the number of coordinate steps per degree, about 11 m at the equator
+const scale = 10000
+
+type ring [][2]int64
+
+type polygon []ring
+
+type zone struct {
+	name     string
+	polygons []polygon
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run gen.go combined-with-oceans.reduce.bin")
+		os.Exit(2)
+	}
+	data, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		fail(err)
+	}
+	zones, err := decodeTimezones(data)
+	if err != nil {
+		fail(err)
+	}
+
+	var raw []byte
+	raw = binary.AppendUvarint(raw, uint64(len(zones)))
+	for _, z := range zones {
+		raw = binary.AppendUvarint(raw, uint64(len(z.name)))
+		raw = append(raw, z.name...)
+		raw = binary.AppendUvarint(raw, uint64(len(z.polygons)))
+		for _, p := range z.polygons {
+			raw = binary.AppendUvarint(raw, uint64(len(p)))
+			for _, r := range p {
+				raw = binary.AppendUvarint(raw, uint64(len(r)))
+				var px, py int64
+				for _, pt := range r {
+					raw = binary.AppendVarint(raw, pt[0]-px)
+					raw = binary.AppendVarint(raw, pt[1]-py)
+					px, py = pt[0], pt[1]
+				}
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	w, err := gzip.NewWriterLevel(&out, gzip.BestCompression)
+	if err != nil {
+		fail(err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		fail(err)
+	}
+	if err := w.Close(); err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile("zones.bin.gz", out.Bytes(), 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// field is one decoded protobuf field
+type field struct {
+	num   uint64
+	value uint64
+	bytes []byte
+}
+
+// fields splits a protobuf message into its fields
+func fields(b []byte) ([]field, error) {
+	var out []field
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("bad field key")
+		}
+		b = b[n:]
+		f := field{num: key >> 3}
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errors.New("bad varint")
+			}
+			f.value, b = v, b[n:]
+		case 1:
+			if len(b) < 8 {
+				return nil, errors.New("short fixed64")
+			}
+			f.bytes, b = b[:8], b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil, errors.New("bad length")
+			}
+			f.bytes, b = b[n:n+int(l)], b[n+int(l):]
+		case 5:
+			if len(b) < 4 {
+				return nil, errors.New("short fixed32")
+			}
+			f.bytes, b = b[:4], b[4:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", key&7)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// decodeTimezones decodes a tzf.v1.Timezones message
+func decodeTimezones(b []byte) ([]zone, error) {
+	fs, err := fields(b)
+	if err != nil {
+		return nil, err
+	}
+	var zones []zone
+	for _, f := range fs {
+		if f.num != 1 {
+			continue
+		}
+		zfs, err := fields(f.bytes)
+		if err != nil {
+			return nil, err
+		}
+		var z zone
+		for _, zf := range zfs {
+			switch zf.num {
+			case 1:
+				p, err := decodePolygon(zf.bytes)
+				if err != nil {
+					return nil, err
+				}
+				if len(p) > 0 {
+					z.polygons = append(z.polygons, p)
+				}
+			case 2:
+				z.name = string(zf.bytes)
+			}
+		}
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
+
+// decodePolygon decodes a tzf.v1.Polygon into its exterior ring followed by
+// its holes
+func decodePolygon(b []byte) (polygon, error) {
+	fs, err := fields(b)
+	if err != nil {
+		return nil, err
+	}
+	var exterior ring
+	var holes []ring
+	for _, f := range fs {
+		switch f.num {
+		case 1:
+			pt, err := decodePoint(f.bytes)
+			if err != nil {
+				return nil, err
+			}
+			exterior = append(exterior, pt)
+		case 2:
+			hole, err := decodePolygon(f.bytes)
+			if err != nil {
+				return nil, err
+			}
+			if len(hole) > 0 {
+				holes = append(holes, hole[0])
+			}
+		}
+	}
+	exterior = cleanRing(exterior)
+	if len(exterior) < 3 {
+		return nil, nil
+	}
+	p := polygon{exterior}
+	for _, h := range holes {
+		if len(h) >= 3 {
+			p = append(p, h)
+		}
+	}
+	return p, nil
+}
+
+// decodePoint decodes a tzf.v1.Point to scaled longitude and latitude
+func decodePoint(b []byte) ([2]int64, error) {
+	fs, err := fields(b)
+	if err != nil {
+		return [2]int64{}, err
+	}
+	var pt [2]int64
+	for _, f := range fs {
+		if (f.num == 1 || f.num == 2) && len(f.bytes) == 4 {
+			v := math.Float32frombits(binary.LittleEndian.Uint32(f.bytes))
+			pt[f.num-1] = int64(math.Round(float64(v) * scale))
+		}
+	}
+	return pt, nil
+}
+
+// cleanRing drops repeated points and the closing point
+func cleanRing(r ring) ring {
+	var out ring
+	for _, pt := range r {
+		if len(out) == 0 || out[len(out)-1] != pt {
+			out = append(out, pt)
+		}
+	}
+	if len(out) > 1 && out[0] == out[len(out)-1] {
+		out = out[:len(out)-1]
+	}
+	return out
+}
@@ -0,0 +1,234 @@
+// Package timezone resolves coordinates to IANA time zones offline from
+// embedded time zone boundary polygons. The polygons are the simplified
+// timezone-boundary-builder 2025b release with oceans published by
+// tzf-rel-lite (ODbL), converted to zones.bin.gz by gen.go.
+package timezone
+
+//go:generate go run gen.go combined-with-oceans.reduce.bin
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	// Zone rules are embedded so lookups work without system tzdata
+	_ "time/tzdata"
+)
+
+// scale is the number of coordinate steps per degree in zones.bin.gz
+const scale = 10000
+
+// zones.bin.gz is a gzipped sequence of varints: the zone count, then for
+// each zone its name length and name, polygon count, and for each polygon
+// its ring count (exterior first, then holes) and for each ring its point
+// count followed by zigzag longitude and latitude deltas in 1/scale degrees.
+//
+//go:embed zones.bin.gz
+var zonesData []byte
+
+// polygon is one area of a zone with its bounding box in scaled degrees
+type polygon struct {
+	zone                   string
+	rings                  [][]int32
+	minX, minY, maxX, maxY int32
+}
+
+var (
+	loadOnce sync.Once
+	polygons []polygon
+	loadErr  error
+
+	locMu     sync.Mutex
+	locations = map[string]*time.Location{}
+)
+
+// loadPolygons decodes the embedded boundaries
+func loadPolygons() {
+	gz, err := gzip.NewReader(bytes.NewReader(zonesData))
+	if err != nil {
+		loadErr = fmt.Errorf("zones.bin.gz: %w", err)
+		return
+	}
+	r := bufio.NewReader(gz)
+	polygons, loadErr = decodeZones(r)
+	if loadErr != nil {
+		loadErr = fmt.Errorf("zones.bin.gz: %w", loadErr)
+	}
+}
+
+// decodeZones reads every zone's polygons
+func decodeZones(r *bufio.Reader) ([]polygon, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	var out []polygon
+	for range count {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		name := make([]byte, n)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		polyCount, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		for range polyCount {
+			ringCount, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			p := polygon{zone: string(name), minX: math.MaxInt32, minY: math.MaxInt32, maxX: math.MinInt32, maxY: math.MinInt32}
+			for range ringCount {
+				pointCount, err := binary.ReadUvarint(r)
+				if err != nil {
+					return nil, err
+				}
+				ring := make([]int32, 0, 2*pointCount)
+				var x, y int64
+				for range pointCount {
+					dx, err := binary.ReadVarint(r)
+					if err != nil {
+						return nil, err
+					}
+					dy, err := binary.ReadVarint(r)
+					if err != nil {
+						return nil, err
+					}
+					x, y = x+dx, y+dy
+					ring = append(ring, int32(x), int32(y))
+				}
+				p.rings = append(p.rings, ring)
+			}
+			if len(p.rings) == 0 {
+				continue
+			}
+			// Holes lie inside the exterior ring, so it alone sets the bounds
+			for i := 0; i < len(p.rings[0]); i += 2 {
+				p.minX = min(p.minX, p.rings[0][i])
+				p.maxX = max(p.maxX, p.rings[0][i])
+				p.minY = min(p.minY, p.rings[0][i+1])
+				p.maxY = max(p.maxY, p.rings[0][i+1])
+			}
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// contains reports whether a scaled point lies inside the polygon and
+// outside all of its holes
+func (p *polygon) contains(x, y float64) bool {
+	if x < float64(p.minX) || x > float64(p.maxX) || y < float64(p.minY) || y > float64(p.maxY) {
+		return false
+	}
+	if !ringContains(p.rings[0], x, y) {
+		return false
+	}
+	for _, hole := range p.rings[1:] {
+		if ringContains(hole, x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains is the even-odd ray casting test
+func ringContains(ring []int32, x, y float64) bool {
+	inside := false
+	n := len(ring) / 2
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		xi, yi := float64(ring[2*i]), float64(ring[2*i+1])
+		xj, yj := float64(ring[2*j]), float64(ring[2*j+1])
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Name returns the IANA time zone name for a coordinate. Land zones take
+// precedence over the nautical Etc/GMT zones that cover the oceans, and the
+// nautical zone for the longitude is used where no polygon matches.
+func Name(lat, lon float64) string {
+	loadOnce.Do(loadPolygons)
+	if loadErr != nil {
+		return nautical(lon)
+	}
+
+	x, y := lon*scale, lat*scale
+	found := ""
+	for i := range polygons {
+		p := &polygons[i]
+		if !p.contains(x, y) {
+			continue
+		}
+		if !strings.HasPrefix(p.zone, "Etc/") {
+			return p.zone
+		}
+		if found == "" {
+			found = p.zone
+		}
+	}
+	if found != "" {
+		return found
+	}
+	return nautical(lon)
+}
+
+// Lookup returns the time zone for a coordinate. It falls back to the
+// nautical zone if the zone's rules cannot be loaded.
+func Lookup(lat, lon float64) *time.Location {
+	name := Name(lat, lon)
+	loc, err := location(name)
+	if err != nil {
+		loc, _ = location(nautical(lon))
+	}
+	return loc
+}
+
+// location loads a zone's rules once
+func location(name string) (*time.Location, error) {
+	locMu.Lock()
+	defer locMu.Unlock()
+	if loc, ok := locations[name]; ok {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations[name] = loc
+	return loc, nil
+}
+
+// nautical returns the Etc/GMT zone whose 15° band contains a longitude.
+// Etc/GMT names use POSIX signs, so Etc/GMT-5 is five hours ahead of UTC.
+func nautical(lon float64) string {
+	hours := int(math.Round(lon / 15))
+	switch {
+	case hours > 12:
+		hours = 12
+	case hours < -12:
+		hours = -12
+	}
+	switch {
+	case hours == 0:
+		return "Etc/GMT"
+	case hours > 0:
+		return fmt.Sprintf("Etc/GMT-%d", hours)
+	default:
+		return fmt.Sprintf("Etc/GMT+%d", -hours)
+	}
+}
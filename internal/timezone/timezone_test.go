@@ -0,0 +1,68 @@
+package timezone
+
+import "testing"
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     string
+	}{
+		// Border cities the nearest-point lookup resolved to a neighbour
+		{"Siliguri", 26.7271, 88.3953, "Asia/Kolkata"},
+		{"Gorakhpur", 26.7606, 83.3732, "Asia/Kolkata"},
+		{"Valença", 42.0287, -8.6447, "Europe/Lisbon"},
+		{"Tui", 42.0476, -8.6447, "Europe/Madrid"},
+		{"North Platte", 41.1403, -100.7601, "America/Chicago"},
+		{"St George", 37.0965, -113.5684, "America/Denver"},
+		{"Mesquite", 36.8055, -114.0672, "America/Los_Angeles"},
+		{"El Paso", 31.7619, -106.4850, "America/Denver"},
+		{"Ciudad Juárez", 31.6904, -106.4245, "America/Ciudad_Juarez"},
+		{"Strasbourg", 48.5734, 7.7521, "Europe/Paris"},
+		{"Kehl", 48.5727, 7.8158, "Europe/Berlin"},
+		{"Narva", 59.3797, 28.1791, "Europe/Tallinn"},
+		{"Ivangorod", 59.3767, 28.2169, "Europe/Moscow"},
+		{"Jaipur", 26.9124, 75.7873, "Asia/Kolkata"},
+		{"Kathmandu", 27.7172, 85.3240, "Asia/Kathmandu"},
+		{"Thimphu", 27.4728, 89.6390, "Asia/Thimphu"},
+		{"Denver", 39.7392, -104.9903, "America/Denver"},
+		{"Phoenix", 33.4484, -112.0740, "America/Phoenix"},
+		{"Tokyo", 35.6762, 139.6503, "Asia/Tokyo"},
+		{"Sydney", -33.8688, 151.2093, "Australia/Sydney"},
+		// Open ocean uses the nautical zones
+		{"mid Atlantic", 30, -40, "Etc/GMT+3"},
+		{"mid Pacific", 0, -160, "Etc/GMT+11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Name(tt.lat, tt.lon); got != tt.want {
+				t.Errorf("Name(%v, %v) = %s, want %s", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	loc := Lookup(26.7271, 88.3953)
+	if loc.String() != "Asia/Kolkata" {
+		t.Fatalf("Lookup = %s, want Asia/Kolkata", loc)
+	}
+}
+
+func TestNautical(t *testing.T) {
+	tests := []struct {
+		lon  float64
+		want string
+	}{
+		{0, "Etc/GMT"},
+		{75, "Etc/GMT-5"},
+		{-75, "Etc/GMT+5"},
+		{179.9, "Etc/GMT-12"},
+		{-179.9, "Etc/GMT+12"},
+	}
+	for _, tt := range tests {
+		if got := nautical(tt.lon); got != tt.want {
+			t.Errorf("nautical(%v) = %s, want %s", tt.lon, got, tt.want)
+		}
+	}
+}
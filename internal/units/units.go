@@ -0,0 +1,155 @@
+// Package units converts pollutant concentrations between mixing ratios and
+// mass concentrations
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unit is a concentration unit. The values of the units the Air Quality API
+// reports match its units field.
+type Unit string
+
+const (
+	MicrogramsPerCubicMeter Unit = "MICROGRAMS_PER_CUBIC_METER"
+	MilligramsPerCubicMeter Unit = "MILLIGRAMS_PER_CUBIC_METER"
+	PartsPerBillion         Unit = "PARTS_PER_BILLION"
+	PartsPerMillion         Unit = "PARTS_PER_MILLION"
+)
+
+// aliases maps accepted spellings to units
+var aliases = map[string]Unit{
+	"ug/m3": MicrogramsPerCubicMeter,
+	"µg/m3": MicrogramsPerCubicMeter,
+	"µg/m³": MicrogramsPerCubicMeter,
+	"mg/m3": MilligramsPerCubicMeter,
+	"mg/m³": MilligramsPerCubicMeter,
+	"ppb":   PartsPerBillion,
+	"ppm":   PartsPerMillion,
+}
+
+// symbols holds the short symbol of every unit
+var symbols = map[Unit]string{
+	MicrogramsPerCubicMeter: "µg/m³",
+	MilligramsPerCubicMeter: "mg/m³",
+	PartsPerBillion:         "ppb",
+	PartsPerMillion:         "ppm",
+}
+
+// Parse accepts a unit name (e.g. PARTS_PER_BILLION) or symbol (e.g. ppb)
+func Parse(s string) (Unit, error) {
+	if u, ok := aliases[strings.ToLower(s)]; ok {
+		return u, nil
+	}
+	u := Unit(strings.ToUpper(s))
+	if _, ok := symbols[u]; ok {
+		return u, nil
+	}
+	return "", fmt.Errorf("unknown units %q, expected ppb, ppm, ug/m3 or mg/m3", s)
+}
+
+// Symbol returns the short symbol of a unit, e.g. ppb
+func (u Unit) Symbol() string {
+	if s, ok := symbols[u]; ok {
+		return s
+	}
+	return string(u)
+}
+
+// isMixingRatio reports whether a unit is a volume mixing ratio
+func (u Unit) isMixingRatio() bool {
+	return u == PartsPerBillion || u == PartsPerMillion
+}
+
+// Conditions are the air temperature and pressure at which mixing ratios and
+// mass concentrations are converted
+type Conditions struct {
+	TemperatureC float64
+	PressureHPa  float64
+}
+
+// StandardConditions are 25°C and 1 atm, the reference used by most air
+// quality standards and indexes
+var StandardConditions = Conditions{TemperatureC: 25, PressureHPa: 1013.25}
+
+// Reference are the conditions Convert uses; the server configures them at
+// startup
+var Reference = StandardConditions
+
+// MolarVolume returns the volume of one mole of air in litres
+func (c Conditions) MolarVolume() float64 {
+	const gasConstant = 0.0831446 // L·bar/(K·mol)
+	return gasConstant * (c.TemperatureC + 273.15) / (c.PressureHPa / 1000)
+}
+
+// MolecularWeights holds the molecular weight (g/mol) of gaseous pollutants
+var MolecularWeights = map[string]float64{
+	"co":   28.01,
+	"no":   30.01,
+	"no2":  46.01,
+	"o3":   48.00,
+	"so2":  64.07,
+	"nh3":  17.03,
+	"c6h6": 78.11,
+}
+
+// Convert converts a pollutant concentration between units at the reference
+// conditions
+func Convert(pollutant string, value float64, from, to Unit) (float64, error) {
+	return ConvertAt(pollutant, value, from, to, Reference)
+}
+
+// ConvertAt converts a pollutant concentration between units at the given
+// conditions. Converting between mixing ratios and mass concentrations needs
+// the pollutant's molecular weight, so particulates can only change mass units.
+func ConvertAt(pollutant string, value float64, from, to Unit, c Conditions) (float64, error) {
+	if from == to {
+		return value, nil
+	}
+	mw, gas := MolecularWeights[pollutant]
+	if from.isMixingRatio() != to.isMixingRatio() {
+		if !gas {
+			return 0, fmt.Errorf("cannot convert %s from %s to %s", pollutant, from.Symbol(), to.Symbol())
+		}
+		if c.TemperatureC <= -273.15 || c.PressureHPa <= 0 {
+			return 0, fmt.Errorf("cannot convert at %v°C and %v hPa", c.TemperatureC, c.PressureHPa)
+		}
+	}
+
+	// Convert to µg/m³ or ppb first, then to the target
+	var ugm3 float64
+	switch from {
+	case MicrogramsPerCubicMeter:
+		ugm3 = value
+	case MilligramsPerCubicMeter:
+		ugm3 = value * 1000
+	case PartsPerBillion, PartsPerMillion:
+		ppb := value
+		if from == PartsPerMillion {
+			ppb *= 1000
+		}
+		if to.isMixingRatio() {
+			if to == PartsPerMillion {
+				return ppb / 1000, nil
+			}
+			return ppb, nil
+		}
+		ugm3 = ppb * mw / c.MolarVolume()
+	default:
+		return 0, fmt.Errorf("unknown units %q", from)
+	}
+
+	switch to {
+	case MicrogramsPerCubicMeter:
+		return ugm3, nil
+	case MilligramsPerCubicMeter:
+		return ugm3 / 1000, nil
+	case PartsPerBillion:
+		return ugm3 * c.MolarVolume() / mw, nil
+	case PartsPerMillion:
+		return ugm3 * c.MolarVolume() / mw / 1000, nil
+	default:
+		return 0, fmt.Errorf("unknown units %q", to)
+	}
+}
@@ -0,0 +1,127 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+var allUnits = []Unit{MicrogramsPerCubicMeter, MilligramsPerCubicMeter, PartsPerBillion, PartsPerMillion}
+
+// TestRoundTrip converts every gas between every pair of units and back
+func TestRoundTrip(t *testing.T) {
+	conditions := []Conditions{
+		StandardConditions,
+		{TemperatureC: 0, PressureHPa: 1013.25},
+		{TemperatureC: 35, PressureHPa: 850},
+	}
+	for pollutant := range MolecularWeights {
+		for _, c := range conditions {
+			for _, from := range allUnits {
+				for _, to := range allUnits {
+					const value = 42.5
+					there, err := ConvertAt(pollutant, value, from, to, c)
+					if err != nil {
+						t.Fatalf("%s %s to %s: %v", pollutant, from.Symbol(), to.Symbol(), err)
+					}
+					back, err := ConvertAt(pollutant, there, to, from, c)
+					if err != nil {
+						t.Fatalf("%s %s to %s: %v", pollutant, to.Symbol(), from.Symbol(), err)
+					}
+					if math.Abs(back-value) > 1e-9*value {
+						t.Errorf("%s at %+v: %v %s -> %v %s -> %v", pollutant, c, value, from.Symbol(), there, to.Symbol(), back)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestConvertAt(t *testing.T) {
+	cold := Conditions{TemperatureC: 0, PressureHPa: 1013.25}
+	tests := []struct {
+		pollutant string
+		value     float64
+		from, to  Unit
+		c         Conditions
+		want      float64
+	}{
+		// 1 ppb is MW / 24.465 µg/m³ at 25°C and 1 atm
+		{"o3", 1, PartsPerBillion, MicrogramsPerCubicMeter, StandardConditions, 1.962},
+		{"no2", 1, PartsPerBillion, MicrogramsPerCubicMeter, StandardConditions, 1.881},
+		{"so2", 1, PartsPerBillion, MicrogramsPerCubicMeter, StandardConditions, 2.619},
+		{"co", 1, PartsPerMillion, MilligramsPerCubicMeter, StandardConditions, 1.145},
+		{"o3", 100, MicrogramsPerCubicMeter, PartsPerBillion, StandardConditions, 50.97},
+		// Colder air is denser, so the same mixing ratio holds more mass
+		{"o3", 1, PartsPerBillion, MicrogramsPerCubicMeter, cold, 2.141},
+		// Mixing ratios and mass units scale without a molecular weight
+		{"o3", 70, PartsPerBillion, PartsPerMillion, StandardConditions, 0.07},
+		{"pm25", 35, MicrogramsPerCubicMeter, MilligramsPerCubicMeter, StandardConditions, 0.035},
+		{"pm25", 12, MicrogramsPerCubicMeter, MicrogramsPerCubicMeter, StandardConditions, 12},
+	}
+	for _, tt := range tests {
+		got, err := ConvertAt(tt.pollutant, tt.value, tt.from, tt.to, tt.c)
+		if err != nil {
+			t.Errorf("%s %v %s to %s: %v", tt.pollutant, tt.value, tt.from.Symbol(), tt.to.Symbol(), err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 0.001*tt.want {
+			t.Errorf("%s %v %s to %s = %v, want %v", tt.pollutant, tt.value, tt.from.Symbol(), tt.to.Symbol(), got, tt.want)
+		}
+	}
+}
+
+func TestConvertAtErrors(t *testing.T) {
+	tests := []struct {
+		pollutant string
+		from, to  Unit
+	}{
+		// Particulates have no molecular weight
+		{"pm25", MicrogramsPerCubicMeter, PartsPerBillion},
+		{"pm10", PartsPerMillion, MilligramsPerCubicMeter},
+		{"o3", "GRAMS", PartsPerBillion},
+		{"o3", PartsPerBillion, "GRAMS"},
+	}
+	for _, tt := range tests {
+		if got, err := ConvertAt(tt.pollutant, 1, tt.from, tt.to, StandardConditions); err == nil {
+			t.Errorf("%s %s to %s = %v, want error", tt.pollutant, tt.from, tt.to, got)
+		}
+	}
+
+	// The molar volume is undefined without pressure or at absolute zero
+	for _, c := range []Conditions{
+		{TemperatureC: 25, PressureHPa: 0},
+		{TemperatureC: 25, PressureHPa: -1013.25},
+		{TemperatureC: -273.15, PressureHPa: 1013.25},
+		{TemperatureC: -300, PressureHPa: 1013.25},
+	} {
+		if got, err := ConvertAt("o3", 1, PartsPerBillion, MicrogramsPerCubicMeter, c); err == nil {
+			t.Errorf("o3 at %+v = %v, want error", c, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Unit
+	}{
+		{"ppb", PartsPerBillion},
+		{"PPM", PartsPerMillion},
+		{"ug/m3", MicrogramsPerCubicMeter},
+		{"µg/m³", MicrogramsPerCubicMeter},
+		{"mg/m3", MilligramsPerCubicMeter},
+		{"PARTS_PER_BILLION", PartsPerBillion},
+		{"micrograms_per_cubic_meter", MicrogramsPerCubicMeter},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "ppt", "g/m3"} {
+		if got, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %q, want error", in, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+	} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2024-05-01 is a Wednesday
+	from := time.Date(2024, 5, 1, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", from, time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)},
+		{"* * * * *", from, time.Date(2024, 5, 1, 10, 8, 0, 0, time.UTC)},
+		{"0 * * * *", from, time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", from, time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)},
+		{"@daily", from, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", from, time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", from, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 6 * * 1-5", from, time.Date(2024, 5, 2, 6, 30, 0, 0, time.UTC)},
+		{"0 9,17 * * *", from, time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", from, time.Date(2024, 5, 1, 10, 25, 0, 0, time.UTC)},
+		{"0-30/10 * * * *", from, time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC)},
+		// Sunday is both 0 and 7
+		{"0 0 * * 7", from, time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches
+		{"0 0 15 * 5", from, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		// A day-of-month step is unrestricted, so the weekday must also match
+		{"0 0 */2 * 5", from, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * 1", from, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * */2", from, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		// Leap day
+		{"0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Exactly on a run time moves to the next one
+		{"*/15 * * * *", time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC), time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)},
+		// Other time zones are read in UTC
+		{"0 12 * * *", time.Date(2024, 5, 1, 13, 0, 0, 0, time.FixedZone("UTC+2", 2*3600)), time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		// Never runs
+		{"0 0 30 2 *", from, time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%v) = %v, want %v", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
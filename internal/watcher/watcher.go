@@ -0,0 +1,216 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/config"
+)
+
+const (
+	// minBackoff is the wait after the first failed poll; it doubles per failure
+	minBackoff = time.Minute
+	// pollGrace is how long a scheduled poll may take before its result is
+	// considered overdue
+	pollGrace = time.Minute
+)
+
+// Result is the outcome of the polls of one watched location
+type Result struct {
+	Location    tools.LatLng                     `json:"location"`
+	Schedule    string                           `json:"schedule"`
+	Response    *tools.CurrentConditionsResponse `json:"response,omitempty"`
+	PolledAt    time.Time                        `json:"polledAt,omitempty"`
+	LastAttempt time.Time                        `json:"lastAttempt,omitempty"`
+	LastError   string                           `json:"lastError,omitempty"`
+	Failures    int                              `json:"failures"`
+	NextPoll    time.Time                        `json:"nextPoll,omitempty"`
+
+	schedule *Schedule
+}
+
+// target is a watched location with its parsed schedule
+type target struct {
+	location tools.LatLng
+	expr     string
+	schedule *Schedule
+}
+
+// Watcher polls current conditions for configured locations on cron
+// schedules and keeps the latest result of each
+type Watcher struct {
+	client     *tools.Client
+	targets    []target
+	jitter     time.Duration
+	maxBackoff time.Duration
+
+	mu      sync.RWMutex
+	results map[string]*Result
+}
+
+// Key identifies a location independently of how its coordinates are written
+func Key(lat, lon float64) string {
+	return fmt.Sprintf("%.5f,%.5f", lat, lon)
+}
+
+// New creates a watcher for the configured locations. Polls are delayed by up
+// to jitter so they don't all hit the API at once, and failed polls back off
+// exponentially up to maxBackoff.
+func New(client *tools.Client, locations []config.WatchLocation, jitter, maxBackoff time.Duration) (*Watcher, error) {
+	w := &Watcher{
+		client:     client,
+		jitter:     jitter,
+		maxBackoff: maxBackoff,
+		results:    map[string]*Result{},
+	}
+	for _, loc := range locations {
+		schedule, err := ParseSchedule(loc.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("watch location %v,%v: %w", loc.Latitude, loc.Longitude, err)
+		}
+		t := target{
+			location: tools.LatLng{Latitude: loc.Latitude, Longitude: loc.Longitude},
+			expr:     loc.Schedule,
+			schedule: schedule,
+		}
+		w.targets = append(w.targets, t)
+		w.results[Key(loc.Latitude, loc.Longitude)] = &Result{Location: t.location, Schedule: t.expr, schedule: schedule}
+	}
+	return w, nil
+}
+
+// Start polls every location once and then on its schedule until ctx is
+// done, calling onUpdate after each successful poll
+func (w *Watcher) Start(ctx context.Context, onUpdate func(location tools.LatLng)) {
+	for _, t := range w.targets {
+		go w.run(ctx, t, onUpdate)
+	}
+	if len(w.targets) > 0 {
+		log.Printf("Watching %d locations", len(w.targets))
+	}
+}
+
+// Watching reports whether a location is polled by the watcher
+func (w *Watcher) Watching(lat, lon float64) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.results[Key(lat, lon)]
+	return ok
+}
+
+// Latest returns a copy of the latest result for a location, if it has been
+// polled successfully
+func (w *Watcher) Latest(lat, lon float64) (*Result, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	result, ok := w.results[Key(lat, lon)]
+	if !ok || result.Response == nil {
+		return nil, false
+	}
+	copied := *result
+	return &copied, true
+}
+
+// Fresh returns a copy of the latest result for a location if it was polled
+// less than maxAge ago and the poll scheduled after it is not overdue, which
+// it is after failed polls back off or while the schedule is far apart
+func (w *Watcher) Fresh(lat, lon float64, maxAge time.Duration, now time.Time) (*Result, bool) {
+	result, ok := w.Latest(lat, lon)
+	if !ok || now.Sub(result.PolledAt) >= maxAge {
+		return nil, false
+	}
+	if due := result.schedule.Next(result.PolledAt); !due.IsZero() && now.After(due.Add(w.jitter+pollGrace)) {
+		return nil, false
+	}
+	return result, true
+}
+
+// run polls one location until ctx is done
+func (w *Watcher) run(ctx context.Context, t target, onUpdate func(location tools.LatLng)) {
+	failures := 0
+	next := time.Now()
+	for {
+		timer := time.NewTimer(time.Until(next) + w.randomJitter())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		err := w.poll(t)
+		now := time.Now()
+		next = t.schedule.Next(now)
+		if err != nil {
+			failures++
+			log.Printf("Watch poll of %v,%v failed (%d in a row): %v", t.location.Latitude, t.location.Longitude, failures, err)
+			// Skip scheduled polls until the backoff has passed
+			if retry := now.Add(w.backoff(failures)); next.IsZero() || retry.After(next) {
+				next = retry
+			}
+		} else {
+			failures = 0
+			if onUpdate != nil {
+				onUpdate(t.location)
+			}
+		}
+		if next.IsZero() {
+			log.Printf("Watch schedule %q of %v,%v never runs again", t.expr, t.location.Latitude, t.location.Longitude)
+			return
+		}
+		w.setNextPoll(t.location, next)
+	}
+}
+
+// poll fetches current conditions for a location and records the result
+func (w *Watcher) poll(t target) error {
+	universalAqi := true
+	resp, err := w.client.GetCurrentConditions(tools.CurrentConditionsRequest{
+		Location:     t.location,
+		UniversalAqi: &universalAqi,
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := w.results[Key(t.location.Latitude, t.location.Longitude)]
+	result.LastAttempt = time.Now().UTC()
+	if err != nil {
+		result.LastError = err.Error()
+		result.Failures++
+		return err
+	}
+	result.Response = resp
+	result.PolledAt = result.LastAttempt
+	result.LastError = ""
+	result.Failures = 0
+	return nil
+}
+
+// setNextPoll records when a location will be polled next
+func (w *Watcher) setNextPoll(location tools.LatLng, next time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.results[Key(location.Latitude, location.Longitude)].NextPoll = next.UTC()
+}
+
+// backoff returns the wait after the given number of consecutive failures
+func (w *Watcher) backoff(failures int) time.Duration {
+	d := minBackoff
+	for i := 1; i < failures && d < w.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, w.maxBackoff)
+}
+
+// randomJitter returns a random delay of up to the configured jitter
+func (w *Watcher) randomJitter() time.Duration {
+	if w.jitter <= 0 {
+		return 0
+	}
+	return rand.N(w.jitter)
+}
@@ -0,0 +1,39 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akshaygalande/google-air-quality-mcp/internal/capabilities/tools"
+	"github.com/akshaygalande/google-air-quality-mcp/internal/config"
+)
+
+func TestFresh(t *testing.T) {
+	polledAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		schedule string
+		now      time.Time
+		want     bool
+	}{
+		{"*/15 * * * *", polledAt.Add(10 * time.Minute), true},
+		// The 10:15 poll is overdue once jitter and grace have passed
+		{"*/15 * * * *", polledAt.Add(16 * time.Minute), true},
+		{"*/15 * * * *", polledAt.Add(17 * time.Minute), false},
+		// A daily schedule is never overdue within the hour, but results
+		// older than maxAge are not served
+		{"@daily", polledAt.Add(59 * time.Minute), true},
+		{"@daily", polledAt.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		w, err := New(nil, []config.WatchLocation{{Latitude: 1, Longitude: 2, Schedule: tt.schedule}}, 30*time.Second, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := w.results[Key(1, 2)]
+		result.Response = &tools.CurrentConditionsResponse{}
+		result.PolledAt = polledAt
+		if _, got := w.Fresh(1, 2, time.Hour, tt.now); got != tt.want {
+			t.Errorf("%s at %v: Fresh = %v, want %v", tt.schedule, tt.now.Sub(polledAt), got, tt.want)
+		}
+	}
+}